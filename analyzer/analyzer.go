@@ -1,32 +1,114 @@
 package analyzer
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"path/filepath"
+	"strings"
 
 	"biliTagAnalyse/cmd"
+	"biliTagAnalyse/crawler"
 	"biliTagAnalyse/statistics"
 )
 
+const (
+	defaultAPIModel = "gpt-3.5-turbo"
+	insightTagCount = 10
+)
+
 type Analyzer struct {
-	opts *cmd.Options
+	opts       *cmd.Options
+	provider   Provider
+	videos     []*crawler.VideoInfo
+	taxonomy   TaxonomyConfig
+	clustering ClusterConfig
+}
+
+// NewAnalyzer 根据运行模式选择模型后端：-provider 指定的名称优先，-ollama 对应 ollama，-api 按 api_format 选择协议。
+// providerConfigs 为 config.json 中的 providers 配置块，JSON 输出模式下不创建后端。
+func NewAnalyzer(opts *cmd.Options, providerConfigs map[string]json.RawMessage) (*Analyzer, error) {
+	a := &Analyzer{opts: opts}
+	if err := ValidateAPIFormat(opts.APIFormat); err != nil {
+		return nil, err
+	}
+
+	name := ProviderName(opts)
+	if name == "" {
+		return a, nil
+	}
+
+	provider, err := NewProvider(name, providerConfigs[name], opts)
+	if err != nil {
+		return nil, err
+	}
+	// 提前检查提示词，避免爬取结束后才发现模板不存在
+	if _, _, err := ParsePromptTemplate(opts.Prompt); err != nil {
+		return nil, err
+	}
+	a.provider = provider
+	return a, nil
+}
+
+// ProviderName 返回当前运行模式使用的后端名称，JSON 输出模式返回空字符串
+func ProviderName(opts *cmd.Options) string {
+	switch {
+	case opts.RunMode == cmd.ModeJSONOnly:
+		return ""
+	case opts.Provider != "":
+		return opts.Provider
+	case opts.RunMode == cmd.ModeOllama:
+		return "ollama"
+	case opts.RunMode == cmd.ModeAPI && opts.APIFormat != "":
+		return opts.APIFormat
+	case opts.RunMode == cmd.ModeAPI:
+		return cmd.DefaultAPIFormat
+	default:
+		return ""
+	}
+}
+
+// SetVideos 提供原始视频数据，分块分析开启 with_titles 时把视频标题也交给模型
+func (a *Analyzer) SetVideos(videos []*crawler.VideoInfo) {
+	a.videos = videos
+}
+
+// SetTaxonomy 设置 -categorize 使用的分类表和缓存文件
+func (a *Analyzer) SetTaxonomy(taxonomy TaxonomyConfig) {
+	a.taxonomy = taxonomy
+}
+
+// SetClustering 设置 -cluster 使用的相似度阈值和向量缓存文件
+func (a *Analyzer) SetClustering(clustering ClusterConfig) {
+	a.clustering = clustering
 }
 
-func NewAnalyzer(opts *cmd.Options) *Analyzer {
-	return &Analyzer{opts: opts}
+// SetResponseCache 为模型后端加上回答缓存，指定 -no-cache 或没有模型后端时不缓存
+func (a *Analyzer) SetResponseCache(cfg ResponseCacheConfig) {
+	if a.provider == nil || a.opts.NoCache {
+		return
+	}
+	a.provider = &cachedProvider{Provider: a.provider, cache: NewResponseCache(cfg)}
 }
 
 type AnalysisResult struct {
-	Summary     string                 `json:"summary"`
-	TopTags     []TagInsight           `json:"top_tags_insights"`
-	Trends      []string               `json:"trends"`
-	Suggestions []string               `json:"suggestions"`
+	Summary     string                  `json:"summary"`
+	TopTags     []TagInsight            `json:"top_tags_insights"`
+	Trends      []string                `json:"trends"`
+	Suggestions []string                `json:"suggestions"`
+	Provider    string                  `json:"provider,omitempty"`
+	Model       string                  `json:"model,omitempty"`
+	Generation  *cmd.GenerationParams   `json:"generation,omitempty"`
+	Warnings    []string                `json:"warnings,omitempty"`
+	MapReduce   *MapReduceInfo          `json:"map_reduce,omitempty"`
+	Prompt      *PromptRecord           `json:"prompt,omitempty"`
+	FromCache   bool                    `json:"from_cache,omitempty"`
+	Cache       *CacheUsage             `json:"cache,omitempty"`
+	Categories  *CategoryReport         `json:"categories,omitempty"`
+	Clusters    *ClusterReport          `json:"clusters,omitempty"`
 	RawStats    *statistics.StatsResult `json:"raw_stats"`
 }
 
@@ -37,21 +119,47 @@ type TagInsight struct {
 }
 
 func (a *Analyzer) Analyze(stats *statistics.StatsResult) (*AnalysisResult, error) {
-	switch a.opts.RunMode {
-	case cmd.ModeJSONOnly:
-		return a.analyzeJSONOnly(stats)
-	case cmd.ModeOllama:
-		return a.analyzeWithOllama(stats)
-	case cmd.ModeAPI:
-		return a.analyzeWithAPI(stats)
-	default:
+	if a.provider == nil {
+		if a.opts.Categorize || a.opts.Cluster {
+			log.Println("JSON文件输出模式没有模型后端，跳过Tag分类和语义聚类")
+		}
 		return a.analyzeJSONOnly(stats)
 	}
+
+	// Ctrl+C 取消正在进行的模型请求，而不是直接结束进程
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := a.analyzeWithProvider(ctx, stats)
+	if err != nil {
+		return nil, err
+	}
+	// 只统计主分析的请求：全部命中缓存时结果视为来自缓存
+	if cp, ok := a.provider.(*cachedProvider); ok {
+		usage := cp.usage
+		result.Cache = &usage
+		result.FromCache = usage.Hits > 0 && usage.Misses == 0
+	}
+	if a.opts.Categorize {
+		categories, err := a.categorize(ctx, stats)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Tag分类失败: %v", err))
+		}
+		result.Categories = categories
+	}
+	if a.opts.Cluster {
+		clusters, err := a.cluster(ctx, stats)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("语义聚类失败: %v", err))
+		}
+		result.Clusters = clusters
+	}
+	return result, nil
 }
 
 func (a *Analyzer) analyzeJSONOnly(stats *statistics.StatsResult) (*AnalysisResult, error) {
 	log.Println("运行模式：JSON文件输出模式（不进行模型分析）")
-	
+
 	result := &AnalysisResult{
 		Summary:     "仅JSON输出模式，未进行模型分析",
 		TopTags:     make([]TagInsight, 0),
@@ -71,230 +179,70 @@ func (a *Analyzer) analyzeJSONOnly(stats *statistics.StatsResult) (*AnalysisResu
 	return result, nil
 }
 
-func (a *Analyzer) analyzeWithOllama(stats *statistics.StatsResult) (*AnalysisResult, error) {
-	log.Printf("运行模式：Ollama本地模型分析 (模型: %s, 地址: %s)", a.opts.OllamaModel, a.opts.OllamaURL)
+func (a *Analyzer) analyzeWithProvider(ctx context.Context, stats *statistics.StatsResult) (*AnalysisResult, error) {
+	log.Printf("运行模式：%s 模型分析 (模型: %s, 生成参数: %s)", a.provider.Name(), a.provider.Model(), a.provider.Params())
 
-	prompt := a.buildAnalysisPrompt(stats)
-	
-	response, err := a.callOllamaAPI(prompt)
+	prompt, err := a.renderPrompt(stats)
 	if err != nil {
-		return nil, fmt.Errorf("Ollama分析失败: %w", err)
+		return nil, err
 	}
+	log.Printf("提示词: %s (%s)", prompt.Name, prompt.Source)
 
-	result := &AnalysisResult{
-		Summary:     response,
-		TopTags:     make([]TagInsight, 0),
-		Trends:      []string{},
-		Suggestions: []string{},
-		RawStats:    stats,
+	if a.opts.MapReduce {
+		return a.analyzeMapReduce(ctx, stats, prompt)
 	}
 
-	for i := 0; i < len(stats.TagStats) && i < 10; i++ {
-		result.TopTags = append(result.TopTags, TagInsight{
-			Tag:         stats.TagStats[i].Tag,
-			Count:       stats.TagStats[i].Count,
-			Description: "由Ollama模型分析",
-		})
+	prompt.Rendered = withInstruction(prompt.Rendered)
+	response, err := a.generate(ctx, prompt.Rendered, true)
+	if err != nil {
+		return nil, err
 	}
 
+	result := a.newResult(stats)
+	result.Prompt = prompt
+	applyStructured(result, stats, a.structuredResponse(ctx, response))
+
 	return result, nil
 }
 
-func (a *Analyzer) analyzeWithAPI(stats *statistics.StatsResult) (*AnalysisResult, error) {
-	log.Printf("运行模式：远程API调用分析 (端点: %s)", a.opts.APIEndpoint)
-
-	prompt := a.buildAnalysisPrompt(stats)
-	
-	response, err := a.callRemoteAPI(prompt)
-	if err != nil {
-		return nil, fmt.Errorf("API分析失败: %w", err)
-	}
-
+// newResult 创建记录后端、模型和生成参数的空分析结果
+func (a *Analyzer) newResult(stats *statistics.StatsResult) *AnalysisResult {
 	result := &AnalysisResult{
-		Summary:     response,
-		TopTags:     make([]TagInsight, 0),
 		Trends:      []string{},
 		Suggestions: []string{},
+		Provider:    a.provider.Name(),
+		Model:       a.provider.Model(),
 		RawStats:    stats,
 	}
-
-	for i := 0; i < len(stats.TagStats) && i < 10; i++ {
-		result.TopTags = append(result.TopTags, TagInsight{
-			Tag:         stats.TagStats[i].Tag,
-			Count:       stats.TagStats[i].Count,
-			Description: "由远程API分析",
-		})
+	if params := a.provider.Params(); !params.IsZero() {
+		result.Generation = &params
 	}
-
-	return result, nil
+	return result
 }
 
-func (a *Analyzer) buildAnalysisPrompt(stats *statistics.StatsResult) string {
-	topTags := ""
-	for i := 0; i < len(stats.TagStats) && i < 20; i++ {
-		topTags += fmt.Sprintf("%d. %s (出现次数: %d)\n", i+1, stats.TagStats[i].Tag, stats.TagStats[i].Count)
-	}
-
-	prompt := fmt.Sprintf(`你是一个B站视频内容分析专家。请分析以下B站推荐视频的Tag统计数据，提供专业的内容洞察。
-
-统计信息：
-- 爬取时间: %s
-- 总视频数: %d
-- 不同Tag数: %d
-
-Top 20 Tags:
-%s
-
-请从以下角度进行分析：
-1. 内容趋势：这些Tag反映了什么样的内容趋势？
-2. 用户偏好：用户对什么类型的内容更感兴趣？
-3. 创作建议：对于UP主有什么创作建议？
-
-请用中文回答，保持简洁专业。`, 
-		stats.CrawlTime,
-		stats.TotalVideos,
-		stats.TotalTags,
-		topTags,
-	)
-
-	return prompt
-}
-
-type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
-}
-
-type OllamaResponse struct {
-	Model     string `json:"model"`
-	CreatedAt string `json:"created_at"`
-	Response  string `json:"response"`
-	Done      bool   `json:"done"`
-}
-
-func (a *Analyzer) callOllamaAPI(prompt string) (string, error) {
-	reqBody := OllamaRequest{
-		Model:  a.opts.OllamaModel,
-		Prompt: prompt,
-		Stream: false,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("序列化请求失败: %w", err)
-	}
-
-	url := a.opts.OllamaURL + "/api/generate"
-	
-	client := &http.Client{Timeout: 120 * time.Second}
-	
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("创建请求失败: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	log.Printf("正在调用Ollama API: %s", url)
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("请求Ollama失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("Ollama返回错误状态码 %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %w", err)
-	}
-
-	var ollamaResp OllamaResponse
-	if err := json.Unmarshal(body, &ollamaResp); err != nil {
-		return "", fmt.Errorf("解析响应失败: %w", err)
-	}
-
-	return ollamaResp.Response, nil
-}
-
-type APIRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-}
-
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type APIResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
-}
-
-func (a *Analyzer) callRemoteAPI(prompt string) (string, error) {
-	reqBody := APIRequest{
-		Model: "gpt-3.5-turbo",
-		Messages: []Message{
-			{Role: "user", Content: prompt},
-		},
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("序列化请求失败: %w", err)
-	}
-
-	client := &http.Client{Timeout: 120 * time.Second}
-	
-	req, err := http.NewRequest("POST", a.opts.APIEndpoint, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("创建请求失败: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	if a.opts.APIKey != "" {
-		req.Header.Set("Authorization", "Bearer "+a.opts.APIKey)
+// generate 发送一次结构化输出请求；stream 为 true 且开启 -stream 时边生成边打印
+func (a *Analyzer) generate(ctx context.Context, prompt string, stream bool) (string, error) {
+	genOpts := GenerateOptions{Schema: analysisSchema}
+	if stream && a.opts.Stream {
+		if a.provider.Capabilities().Stream {
+			log.Println("模型输出：")
+			genOpts.OnToken = func(token string) { fmt.Print(token) }
+		} else {
+			log.Printf("%s 不支持流式输出，等待完整回答", a.provider.Name())
+		}
 	}
 
-	log.Printf("正在调用远程API: %s", a.opts.APIEndpoint)
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("请求远程API失败: %w", err)
+	response, err := a.provider.Complete(ctx, prompt, genOpts)
+	if genOpts.OnToken != nil {
+		fmt.Println()
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API返回错误状态码 %d: %s", resp.StatusCode, string(body))
-	}
-
-	var apiResp APIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return "", fmt.Errorf("解析响应失败: %w", err)
-	}
-
-	if apiResp.Error != nil {
-		return "", fmt.Errorf("API错误: %s", apiResp.Error.Message)
-	}
-
-	if len(apiResp.Choices) == 0 {
-		return "", fmt.Errorf("API返回空响应")
+		if response != "" {
+			return "", fmt.Errorf("%s分析失败（已收到 %d 字）: %w", a.provider.Name(), len([]rune(response)), err)
+		}
+		return "", fmt.Errorf("%s分析失败: %w", a.provider.Name(), err)
 	}
-
-	return apiResp.Choices[0].Message.Content, nil
+	return response, nil
 }
 
 func LoadStatsFromFile(path string) (*statistics.StatsResult, error) {
@@ -311,6 +259,27 @@ func LoadStatsFromFile(path string) (*statistics.StatsResult, error) {
 	return &stats, nil
 }
 
+// LoadAnalysisResult 读取 analysis_result.json；若文件只是统计结果或原始数据，则包装成没有模型结论的分析结果。
+func LoadAnalysisResult(path string) (*AnalysisResult, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取文件失败: %w", err)
+		}
+
+		var result AnalysisResult
+		if err := json.Unmarshal(data, &result); err == nil && result.RawStats != nil {
+			return &result, nil
+		}
+	}
+
+	stats, err := statistics.LoadDataset(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AnalysisResult{RawStats: stats}, nil
+}
+
 func SaveAnalysisResult(result *AnalysisResult, outputPath string) error {
 	dir := outputPath
 	lastSlash := -1
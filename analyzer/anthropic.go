@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"biliTagAnalyse/cmd"
+)
+
+const (
+	defaultAnthropicEndpoint  = "https://api.anthropic.com/v1/messages"
+	defaultAnthropicModel     = "claude-3-5-haiku-latest"
+	defaultAnthropicVersion   = "2023-06-01"
+	defaultAnthropicMaxTokens = 4096
+)
+
+func init() {
+	RegisterProvider("anthropic", newAnthropicProvider)
+}
+
+// AnthropicConfig 对应 Anthropic Messages API。该接口要求 max_tokens，未设置时使用 4096；不支持 seed。
+type AnthropicConfig struct {
+	Endpoint string `json:"endpoint"`
+	APIKey   string `json:"api_key"`
+	Model    string `json:"model"`
+	Version  string `json:"version"`
+	cmd.GenerationParams
+}
+
+type anthropicProvider struct {
+	cfg AnthropicConfig
+}
+
+func newAnthropicProvider(raw json.RawMessage, opts *cmd.Options) (Provider, error) {
+	var cfg AnthropicConfig
+	if err := decodeProviderConfig(raw, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = explicitEndpoint(opts, "anthropic")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = defaultAnthropicEndpoint
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = opts.APIKey
+	}
+	if cfg.Model == "" {
+		cfg.Model = opts.APIModel
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaultAnthropicModel
+	}
+	if cfg.Version == "" {
+		cfg.Version = defaultAnthropicVersion
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("Anthropic 接口需要指定 -api-key")
+	}
+
+	cfg.GenerationParams.Fill(opts.Generation)
+	if cfg.MaxTokens == nil {
+		maxTokens := defaultAnthropicMaxTokens
+		cfg.MaxTokens = &maxTokens
+	}
+	if cfg.Seed != nil {
+		log.Printf("Anthropic 接口不支持 seed，已忽略")
+		cfg.Seed = nil
+	}
+	return &anthropicProvider{cfg: cfg}, nil
+}
+
+func (p *anthropicProvider) Name() string  { return "anthropic" }
+func (p *anthropicProvider) Model() string { return p.cfg.Model }
+
+func (p *anthropicProvider) EmbeddingModel() string { return "" }
+
+func (p *anthropicProvider) CacheScope() string { return p.cfg.Endpoint }
+
+func (p *anthropicProvider) Params() cmd.GenerationParams { return p.cfg.GenerationParams }
+
+func (p *anthropicProvider) Capabilities() Capabilities {
+	return Capabilities{Chat: true}
+}
+
+type anthropicRequest struct {
+	Model         string             `json:"model"`
+	System        string             `json:"system,omitempty"`
+	Messages      []anthropicMessage `json:"messages"`
+	MaxTokens     int                `json:"max_tokens"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	TopP          *float64           `json:"top_p,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+}
+
+func (p *anthropicProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	return p.Chat(ctx, []Message{{Role: "user", Content: prompt}}, opts)
+}
+
+// Chat 将 system 消息合并到顶层 system 字段，其余消息转换为 content blocks
+func (p *anthropicProvider) Chat(ctx context.Context, messages []Message, opts GenerateOptions) (string, error) {
+	system, rest := splitSystem(messages)
+	reqBody := anthropicRequest{
+		Model:         p.cfg.Model,
+		System:        system,
+		MaxTokens:     *p.cfg.MaxTokens,
+		Temperature:   p.cfg.Temperature,
+		TopP:          p.cfg.TopP,
+		StopSequences: p.cfg.Stop,
+	}
+	for _, m := range rest {
+		reqBody.Messages = append(reqBody.Messages, anthropicMessage{
+			Role:    m.Role,
+			Content: []anthropicContentBlock{{Type: "text", Text: m.Content}},
+		})
+	}
+
+	headers := map[string]string{
+		"x-api-key":         p.cfg.APIKey,
+		"anthropic-version": p.cfg.Version,
+	}
+
+	log.Printf("正在调用Anthropic API: %s", p.cfg.Endpoint)
+	body, err := postJSON(ctx, p.cfg.Endpoint, headers, reqBody, anthropicError)
+	if err != nil {
+		return "", fmt.Errorf("请求Anthropic失败: %w", err)
+	}
+
+	var resp anthropicResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("Anthropic返回空响应 (stop_reason: %s)", resp.StopReason)
+	}
+	if resp.StopReason == "max_tokens" {
+		log.Printf("Anthropic 回答因 max_tokens=%d 被截断", *p.cfg.MaxTokens)
+	}
+	return text.String(), nil
+}
+
+func (p *anthropicProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return nil, fmt.Errorf("Anthropic 接口不提供文本向量")
+}
+
+// anthropicError 解析 {"type":"error","error":{"type":"...","message":"..."}}
+func anthropicError(status int, body []byte) error {
+	var resp struct {
+		Error *struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Error != nil && resp.Error.Message != "" {
+		return fmt.Errorf("Anthropic返回错误状态码 %d (%s): %s", status, resp.Error.Type, resp.Error.Message)
+	}
+	return fmt.Errorf("Anthropic返回错误状态码 %d: %s", status, string(body))
+}
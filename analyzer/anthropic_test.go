@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"biliTagAnalyse/cmd"
+)
+
+// -api-endpoint 默认是 OpenAI 兼容地址，只有 -api-format anthropic 时才能用于 Anthropic
+func TestAnthropicEndpointFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		opts cmd.Options
+		want string
+	}{
+		{"未指定地址", cmd.Options{APIKey: "k"}, defaultAnthropicEndpoint},
+		{"通过 -provider 选择", cmd.Options{APIKey: "k", Provider: "anthropic", APIEndpoint: "https://api.openai.com/v1/chat/completions"}, defaultAnthropicEndpoint},
+		{"其他协议的地址", cmd.Options{APIKey: "k", APIFormat: "openai", APIEndpoint: "https://api.openai.com/v1/chat/completions"}, defaultAnthropicEndpoint},
+		{"-api-format anthropic", cmd.Options{APIKey: "k", APIFormat: "anthropic", APIEndpoint: "https://proxy.example.com/v1/messages"}, "https://proxy.example.com/v1/messages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := newAnthropicProvider(nil, &tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := provider.(*anthropicProvider).cfg.Endpoint; got != tt.want {
+				t.Errorf("endpoint = %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+
+	opts := cmd.Options{APIKey: "k", APIEndpoint: "https://api.openai.com/v1/chat/completions"}
+	provider, err := newAnthropicProvider(json.RawMessage(`{"endpoint":"https://gateway.example.com/v1/messages"}`), &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := provider.(*anthropicProvider).cfg.Endpoint; got != "https://gateway.example.com/v1/messages" {
+		t.Errorf("providers 配置块中的 endpoint 应优先，实际 %s", got)
+	}
+}
+
+func TestAnthropicChat(t *testing.T) {
+	var got anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-ant-test" || r.Header.Get("anthropic-version") != defaultAnthropicVersion {
+			t.Errorf("请求头不正确: x-api-key=%q anthropic-version=%q", r.Header.Get("x-api-key"), r.Header.Get("anthropic-version"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("请求体不是合法 JSON: %v", err)
+		}
+		io.WriteString(w, `{"content":[{"type":"text","text":"你好"},{"type":"tool_use"},{"type":"text","text":"世界"}],"stop_reason":"end_turn"}`)
+	}))
+	defer server.Close()
+
+	opts := cmd.Options{APIKey: "sk-ant-test", APIFormat: "anthropic", APIEndpoint: server.URL}
+	provider, err := newAnthropicProvider(nil, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := provider.Chat(context.Background(), []Message{
+		{Role: "system", Content: "你是分析师"},
+		{Role: "user", Content: "分析"},
+	}, GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "你好世界" {
+		t.Errorf("回答 = %q，期望只拼接 text 块", text)
+	}
+
+	if got.System != "你是分析师" || got.MaxTokens != defaultAnthropicMaxTokens || got.Model != defaultAnthropicModel {
+		t.Errorf("请求字段不正确: system=%q max_tokens=%d model=%s", got.System, got.MaxTokens, got.Model)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" || got.Messages[0].Content[0].Type != "text" || got.Messages[0].Content[0].Text != "分析" {
+		t.Errorf("system 消息应移到顶层，其余消息转为 content blocks: %+v", got.Messages)
+	}
+}
+
+func TestAnthropicErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(529)
+		io.WriteString(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	}))
+	defer server.Close()
+
+	opts := cmd.Options{APIKey: "k", APIFormat: "anthropic", APIEndpoint: server.URL}
+	provider, err := newAnthropicProvider(nil, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.Complete(context.Background(), "分析", GenerateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "529 (overloaded_error): Overloaded") {
+		t.Fatalf("错误信息应包含状态码、错误类型和说明，实际: %v", err)
+	}
+}
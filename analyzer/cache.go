@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"biliTagAnalyse/cmd"
+)
+
+const (
+	defaultResponseCacheDir = "results/llm_cache"
+	defaultResponseCacheTTL = 168
+)
+
+// ResponseCacheConfig 对应 config.json 中的 response_cache 配置块：Dir 为缓存目录，TTLHours 为有效期（小时）
+type ResponseCacheConfig struct {
+	Dir      string `json:"dir"`
+	TTLHours int    `json:"ttl_hours"`
+}
+
+// ResponseCache 是按内容寻址的模型回答缓存，每条回答保存为 <dir>/<sha256>.json
+type ResponseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+type cacheEntry struct {
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+	Response  string    `json:"response"`
+}
+
+// CacheStats 是 cache stats 命令输出的缓存概况
+type CacheStats struct {
+	Dir     string
+	TTL     time.Duration
+	Entries int
+	Expired int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// CacheUsage 记录一次分析中模型请求命中缓存的次数
+type CacheUsage struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+func NewResponseCache(cfg ResponseCacheConfig) *ResponseCache {
+	if cfg.Dir == "" {
+		cfg.Dir = defaultResponseCacheDir
+	}
+	if cfg.TTLHours <= 0 {
+		cfg.TTLHours = defaultResponseCacheTTL
+	}
+	return &ResponseCache{dir: cfg.Dir, ttl: time.Duration(cfg.TTLHours) * time.Hour}
+}
+
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *ResponseCache) get(key string) (string, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || time.Since(entry.CreatedAt) > c.ttl {
+		return "", false
+	}
+	return entry.Response, true
+}
+
+func (c *ResponseCache) put(key string, entry cacheEntry) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化缓存失败: %w", err)
+	}
+	if err := os.WriteFile(c.path(key), data, 0o644); err != nil {
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+	return nil
+}
+
+// walk 遍历缓存目录中的每条记录，目录不存在时视为空缓存
+func (c *ResponseCache) walk(fn func(path string, info os.FileInfo, entry cacheEntry)) error {
+	files, err := os.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取缓存目录失败: %w", err)
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(c.dir, f.Name())
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		var entry cacheEntry
+		if data, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(data, &entry)
+		}
+		fn(path, info, entry)
+	}
+	return nil
+}
+
+func (c *ResponseCache) Stats() (*CacheStats, error) {
+	stats := &CacheStats{Dir: c.dir, TTL: c.ttl}
+	err := c.walk(func(path string, info os.FileInfo, entry cacheEntry) {
+		stats.Entries++
+		stats.Bytes += info.Size()
+		if time.Since(entry.CreatedAt) > c.ttl {
+			stats.Expired++
+		}
+		if !entry.CreatedAt.IsZero() && (stats.Oldest.IsZero() || entry.CreatedAt.Before(stats.Oldest)) {
+			stats.Oldest = entry.CreatedAt
+		}
+		if entry.CreatedAt.After(stats.Newest) {
+			stats.Newest = entry.CreatedAt
+		}
+	})
+	return stats, err
+}
+
+// Clear 删除缓存记录，expiredOnly 为 true 时只删除过期的记录，返回删除的条数
+func (c *ResponseCache) Clear(expiredOnly bool) (int, error) {
+	removed := 0
+	var removeErr error
+	err := c.walk(func(path string, info os.FileInfo, entry cacheEntry) {
+		if expiredOnly && time.Since(entry.CreatedAt) <= c.ttl {
+			return
+		}
+		if err := os.Remove(path); err != nil {
+			removeErr = fmt.Errorf("删除缓存失败: %w", err)
+			return
+		}
+		removed++
+	})
+	if err != nil {
+		return removed, err
+	}
+	return removed, removeErr
+}
+
+// cachedProvider 为 Complete 和 Chat 加上回答缓存，键由后端、模型、CacheScope（端点地址等后端设置）、
+// 生成参数、输出格式和提示词共同决定；
+// Embed 直接透传（向量由语义聚类自行缓存）。
+type cachedProvider struct {
+	Provider
+	cache *ResponseCache
+	usage CacheUsage
+}
+
+func (p *cachedProvider) cacheKey(kind string, messages []Message, schema any) string {
+	data, _ := json.Marshal(struct {
+		Provider string               `json:"provider"`
+		Model    string               `json:"model"`
+		Scope    string               `json:"scope,omitempty"`
+		Params   cmd.GenerationParams `json:"params"`
+		Kind     string               `json:"kind"`
+		Schema   any                  `json:"schema,omitempty"`
+		Messages []Message            `json:"messages"`
+	}{p.Name(), p.Model(), p.CacheScope(), p.Params(), kind, schema, messages})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (p *cachedProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	key := p.cacheKey("complete", []Message{{Role: "user", Content: prompt}}, opts.Schema)
+	return p.cached(key, opts, func() (string, error) { return p.Provider.Complete(ctx, prompt, opts) })
+}
+
+func (p *cachedProvider) Chat(ctx context.Context, messages []Message, opts GenerateOptions) (string, error) {
+	key := p.cacheKey("chat", messages, opts.Schema)
+	return p.cached(key, opts, func() (string, error) { return p.Provider.Chat(ctx, messages, opts) })
+}
+
+// cached 命中时直接返回缓存的回答（流式模式下一次性输出），否则调用后端并缓存成功的回答
+func (p *cachedProvider) cached(key string, opts GenerateOptions, call func() (string, error)) (string, error) {
+	if response, ok := p.cache.get(key); ok {
+		p.usage.Hits++
+		log.Printf("使用缓存的模型回答 (%s)", key[:12])
+		if opts.OnToken != nil {
+			opts.OnToken(response)
+		}
+		return response, nil
+	}
+
+	p.usage.Misses++
+	response, err := call()
+	if err != nil {
+		return response, err
+	}
+	entry := cacheEntry{Provider: p.Name(), Model: p.Model(), CreatedAt: time.Now(), Response: response}
+	if err := p.cache.put(key, entry); err != nil {
+		log.Printf("保存模型回答缓存失败: %v", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"biliTagAnalyse/cmd"
+)
+
+// countingProvider 记录实际发给后端的请求次数
+type countingProvider struct {
+	mockProvider
+	calls int
+	scope string
+}
+
+func (p *countingProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	p.calls++
+	return "回答：" + prompt, nil
+}
+
+func (p *countingProvider) CacheScope() string { return p.scope }
+
+func newCountingProvider(scope string) *countingProvider {
+	return &countingProvider{mockProvider: mockProvider{cfg: MockConfig{Model: "m"}}, scope: scope}
+}
+
+func TestCachedProviderHitAndMiss(t *testing.T) {
+	cache := NewResponseCache(ResponseCacheConfig{Dir: t.TempDir()})
+	backend := newCountingProvider("http://a")
+	p := &cachedProvider{Provider: backend, cache: cache}
+	ctx := context.Background()
+
+	first, err := p.Complete(ctx, "分析", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed string
+	second, err := p.Complete(ctx, "分析", GenerateOptions{OnToken: func(token string) { streamed += token }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.calls != 1 || first != second {
+		t.Fatalf("相同请求应命中缓存: 请求 %d 次，回答 %q / %q", backend.calls, first, second)
+	}
+	if streamed != second {
+		t.Errorf("命中缓存时流式回调应一次性收到完整回答，实际 %q", streamed)
+	}
+
+	if _, err := p.Complete(ctx, "另一个提示词", GenerateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Complete(ctx, "分析", GenerateOptions{Schema: analysisSchema}); err != nil {
+		t.Fatal(err)
+	}
+	if backend.calls != 3 {
+		t.Errorf("提示词或输出格式不同时不应命中缓存，请求 %d 次", backend.calls)
+	}
+	if p.usage != (CacheUsage{Hits: 1, Misses: 3}) {
+		t.Errorf("缓存用量 = %+v", p.usage)
+	}
+}
+
+func TestCacheKeyIncludesScopeAndParams(t *testing.T) {
+	base := &cachedProvider{Provider: newCountingProvider("http://a")}
+	key := base.cacheKey("complete", []Message{{Role: "user", Content: "分析"}}, nil)
+
+	other := &cachedProvider{Provider: newCountingProvider("http://b")}
+	if other.cacheKey("complete", []Message{{Role: "user", Content: "分析"}}, nil) == key {
+		t.Error("端点不同的后端不应共用缓存")
+	}
+
+	temperature := 0.2
+	tuned := newCountingProvider("http://a")
+	tuned.cfg.GenerationParams = cmd.GenerationParams{Temperature: &temperature}
+	if (&cachedProvider{Provider: tuned}).cacheKey("complete", []Message{{Role: "user", Content: "分析"}}, nil) == key {
+		t.Error("生成参数不同时不应共用缓存")
+	}
+
+	ollama := func(numCtx int) string {
+		p, err := newOllamaProvider(nil, &cmd.Options{OllamaURL: "http://localhost:11434", OllamaModel: "qwen", ContextWindow: numCtx})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return (&cachedProvider{Provider: p}).cacheKey("complete", nil, nil)
+	}
+	if ollama(8192) == ollama(32768) {
+		t.Error("Ollama 的 num_ctx 不同时不应共用缓存")
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	cache := NewResponseCache(ResponseCacheConfig{Dir: t.TempDir(), TTLHours: 1})
+	if err := cache.put("fresh", cacheEntry{CreatedAt: time.Now(), Response: "新"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.put("stale", cacheEntry{CreatedAt: time.Now().Add(-2 * time.Hour), Response: "旧"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if response, ok := cache.get("fresh"); !ok || response != "新" {
+		t.Errorf("未过期的记录应命中: %q %v", response, ok)
+	}
+	if _, ok := cache.get("stale"); ok {
+		t.Error("超过有效期的记录不应命中")
+	}
+	if _, ok := cache.get("missing"); ok {
+		t.Error("不存在的记录不应命中")
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 2 || stats.Expired != 1 || stats.Bytes == 0 || !stats.Oldest.Before(stats.Newest) {
+		t.Errorf("缓存概况 = %+v", stats)
+	}
+}
+
+func TestResponseCacheClear(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewResponseCache(ResponseCacheConfig{Dir: dir, TTLHours: 1})
+	for key, age := range map[string]time.Duration{"a": 0, "b": 2 * time.Hour, "c": 3 * time.Hour} {
+		if err := cache.put(key, cacheEntry{CreatedAt: time.Now().Add(-age)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 非缓存文件不受影响
+	if err := os.WriteFile(filepath.Join(dir, "README.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := cache.Clear(true)
+	if err != nil || removed != 2 {
+		t.Fatalf("只清理过期记录应删除 2 条，实际 %d，%v", removed, err)
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Error("未过期的记录不应被删除")
+	}
+
+	removed, err = cache.Clear(false)
+	if err != nil || removed != 1 {
+		t.Fatalf("全部清理应删除剩余 1 条，实际 %d，%v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README.txt")); err != nil {
+		t.Errorf("非缓存文件不应被删除: %v", err)
+	}
+
+	// 目录不存在时视为空缓存
+	missing := NewResponseCache(ResponseCacheConfig{Dir: filepath.Join(dir, "missing")})
+	if removed, err := missing.Clear(false); err != nil || removed != 0 {
+		t.Errorf("目录不存在时应返回 0，实际 %d，%v", removed, err)
+	}
+}
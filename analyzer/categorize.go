@@ -0,0 +1,313 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"biliTagAnalyse/statistics"
+)
+
+const (
+	defaultCategoryCacheFile = "results/tag_categories.json"
+	defaultCategoryBatchSize = 50
+	defaultCategoryMaxTags   = 500
+	defaultMinConfidence     = 0.6
+	// FallbackCategory 是模型无法归类或返回了分类表之外的分类时使用的分类
+	FallbackCategory = "其他"
+)
+
+// DefaultCategories 是未配置 taxonomy.categories 时使用的分类表，大致对应B站主分区
+var DefaultCategories = []string{
+	"游戏", "音乐", "知识", "科技", "生活", "美食", "动画", "影视", "娱乐", "运动", "汽车", "时尚", "动物", FallbackCategory,
+}
+
+// TaxonomyConfig 对应 config.json 中的 taxonomy 配置块，零值字段使用默认值
+type TaxonomyConfig struct {
+	Categories    []string `json:"categories"`
+	CacheFile     string   `json:"cache_file"`
+	BatchSize     int      `json:"batch_size"`
+	MaxTags       int      `json:"max_tags"`
+	MinConfidence float64  `json:"min_confidence"`
+}
+
+func (c TaxonomyConfig) withDefaults() TaxonomyConfig {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, category := range c.Categories {
+		if category = strings.TrimSpace(category); category != "" && !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) == 0 {
+		categories = DefaultCategories
+	} else if !seen[FallbackCategory] {
+		categories = append(categories, FallbackCategory)
+	}
+	c.Categories = categories
+
+	if c.CacheFile == "" {
+		c.CacheFile = defaultCategoryCacheFile
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultCategoryBatchSize
+	}
+	if c.MaxTags <= 0 {
+		c.MaxTags = defaultCategoryMaxTags
+	}
+	if c.MinConfidence <= 0 {
+		c.MinConfidence = defaultMinConfidence
+	}
+	return c
+}
+
+// CachedCategory 是分类缓存文件中的一条记录。手动把 manual 设为 true 的记录视为人工确认，不会被重新分类或标记复核。
+type CachedCategory struct {
+	Category   string    `json:"category"`
+	Confidence float64   `json:"confidence"`
+	Model      string    `json:"model,omitempty"`
+	Manual     bool      `json:"manual,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CategoryReport 是 Tag 分类结果，写入 analysis_result.json 的 categories 字段
+type CategoryReport struct {
+	Taxonomy     []string                  `json:"taxonomy"`
+	CacheFile    string                    `json:"cache_file"`
+	Classified   int                       `json:"classified"`
+	CacheHits    int                       `json:"cache_hits"`
+	Unclassified int                       `json:"unclassified"`
+	NeedsReview  int                       `json:"needs_review"`
+	Categories   []statistics.CategoryStat `json:"categories"`
+	Tags         []TagCategory             `json:"tags"`
+}
+
+type TagCategory struct {
+	Tag         string  `json:"tag"`
+	Count       int     `json:"count"`
+	Category    string  `json:"category"`
+	Confidence  float64 `json:"confidence"`
+	Cached      bool    `json:"cached,omitempty"`
+	Manual      bool    `json:"manual,omitempty"`
+	NeedsReview bool    `json:"needs_review,omitempty"`
+}
+
+type categoryAssignment struct {
+	Tag        string  `json:"tag"`
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+}
+
+// LoadCategoryCache 读取分类缓存文件，文件不存在时返回空缓存
+func LoadCategoryCache(path string) (map[string]CachedCategory, error) {
+	cache := make(map[string]CachedCategory)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取分类缓存失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("解析分类缓存失败: %w", err)
+	}
+	return cache, nil
+}
+
+func saveCategoryCache(path string, cache map[string]CachedCategory) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建分类缓存目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化分类缓存失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("写入分类缓存失败: %w", err)
+	}
+	return nil
+}
+
+func categorySchema(categories []string) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"assignments": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"tag":        map[string]any{"type": "string"},
+						"category":   map[string]any{"type": "string", "enum": categories},
+						"confidence": map[string]any{"type": "number"},
+					},
+					"required": []string{"tag", "category", "confidence"},
+				},
+			},
+		},
+		"required": []string{"assignments"},
+	}
+}
+
+func buildCategoryPrompt(categories, tags []string) string {
+	return fmt.Sprintf(`你是一个B站视频内容分类专家。请把下面每个B站视频 Tag 归入以下分类之一：%s。
+无法判断或不属于任何分类时归入“%s”。confidence 为 0~1 之间的置信度，Tag 含义模糊或可能属于多个分类时给出较低的置信度。
+
+Tag 列表（每行一个）：
+%s
+
+请严格只输出一个 JSON 对象，不要输出 Markdown 代码块或其他文字，格式如下：
+{"assignments": [{"tag": "Tag名称", "category": "分类", "confidence": 0.9}]}
+每个 Tag 都必须出现一次，tag 字段必须与列表中的名称完全一致，category 必须是上面列出的分类之一。`,
+		strings.Join(categories, "、"),
+		FallbackCategory,
+		strings.Join(tags, "\n"),
+	)
+}
+
+// classifyBatch 让模型为一批 Tag 分类，返回以 Tag 为键的结果；不在分类表中的分类改为“其他”并把置信度置 0
+func (a *Analyzer) classifyBatch(ctx context.Context, taxonomy TaxonomyConfig, tags []string) (map[string]categoryAssignment, error) {
+	response, err := a.provider.Complete(ctx, buildCategoryPrompt(taxonomy.Categories, tags), GenerateOptions{Schema: categorySchema(taxonomy.Categories)})
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Assignments []categoryAssignment `json:"assignments"`
+	}
+	if err := decodeRepaired(response, &parsed); err != nil {
+		return nil, err
+	}
+
+	valid := make(map[string]bool, len(taxonomy.Categories))
+	for _, category := range taxonomy.Categories {
+		valid[category] = true
+	}
+	requested := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		requested[tag] = true
+	}
+
+	result := make(map[string]categoryAssignment, len(tags))
+	for _, item := range parsed.Assignments {
+		item.Tag = strings.TrimSpace(item.Tag)
+		item.Category = strings.TrimSpace(item.Category)
+		if !requested[item.Tag] {
+			continue
+		}
+		if !valid[item.Category] {
+			log.Printf("Tag %q 的分类 %q 不在分类表中，归入%s", item.Tag, item.Category, FallbackCategory)
+			item.Category, item.Confidence = FallbackCategory, 0
+		}
+		item.Confidence = min(max(item.Confidence, 0), 1)
+		result[item.Tag] = item
+	}
+	return result, nil
+}
+
+// categorize 为出现次数最多的 max_tags 个 Tag 分类并按分类汇总：缓存中已有且分类仍在分类表中的 Tag 直接复用，
+// 其余按 batch_size 分批交给模型，新结果写回缓存文件。单批失败只记录日志，该批 Tag 计为未分类，下次运行重试。
+func (a *Analyzer) categorize(ctx context.Context, stats *statistics.StatsResult) (*CategoryReport, error) {
+	taxonomy := a.taxonomy.withDefaults()
+	cache, err := LoadCategoryCache(taxonomy.CacheFile)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := make(map[string]bool, len(taxonomy.Categories))
+	for _, category := range taxonomy.Categories {
+		valid[category] = true
+	}
+
+	tagStats := stats.TagStats
+	if len(tagStats) > taxonomy.MaxTags {
+		tagStats = tagStats[:taxonomy.MaxTags]
+	}
+
+	report := &CategoryReport{Taxonomy: taxonomy.Categories, CacheFile: taxonomy.CacheFile}
+	var pending []string
+	fresh := make(map[string]bool)
+	for _, tag := range tagStats {
+		if entry, ok := cache[tag.Tag]; ok && (entry.Manual || valid[entry.Category]) {
+			report.CacheHits++
+			continue
+		}
+		pending = append(pending, tag.Tag)
+	}
+	log.Printf("Tag 分类：%d 个 Tag，缓存命中 %d 个，需要分类 %d 个（每批 %d 个）",
+		len(tagStats), report.CacheHits, len(pending), taxonomy.BatchSize)
+
+	for start := 0; start < len(pending); start += taxonomy.BatchSize {
+		if ctx.Err() != nil {
+			log.Printf("Tag 分类已取消，剩余 %d 个 Tag 未分类", len(pending)-start)
+			break
+		}
+		batch := pending[start:min(start+taxonomy.BatchSize, len(pending))]
+		log.Printf("Tag 分类：第 %d/%d 批（%d 个 Tag）", start/taxonomy.BatchSize+1,
+			(len(pending)+taxonomy.BatchSize-1)/taxonomy.BatchSize, len(batch))
+
+		assignments, err := a.classifyBatch(ctx, taxonomy, batch)
+		if err != nil {
+			log.Printf("第 %d 批分类失败，跳过: %v", start/taxonomy.BatchSize+1, err)
+			continue
+		}
+		now := time.Now()
+		for _, item := range assignments {
+			cache[item.Tag] = CachedCategory{
+				Category:   item.Category,
+				Confidence: item.Confidence,
+				Model:      a.provider.Name() + "/" + a.provider.Model(),
+				UpdatedAt:  now,
+			}
+			fresh[item.Tag] = true
+			report.Classified++
+		}
+		if missing := len(batch) - len(assignments); missing > 0 {
+			log.Printf("模型漏掉了 %d 个 Tag，下次运行时重新分类", missing)
+		}
+		if err := saveCategoryCache(taxonomy.CacheFile, cache); err != nil {
+			return nil, err
+		}
+	}
+
+	assign := make(map[string]string, len(tagStats))
+	for _, tag := range tagStats {
+		entry, ok := cache[tag.Tag]
+		if !ok || (!entry.Manual && !valid[entry.Category]) {
+			report.Unclassified++
+			continue
+		}
+		assign[tag.Tag] = entry.Category
+
+		item := TagCategory{
+			Tag:        tag.Tag,
+			Count:      tag.Count,
+			Category:   entry.Category,
+			Confidence: entry.Confidence,
+			Cached:     !fresh[tag.Tag],
+			Manual:     entry.Manual,
+		}
+		if !entry.Manual && entry.Confidence < taxonomy.MinConfidence {
+			item.NeedsReview = true
+			report.NeedsReview++
+		}
+		report.Tags = append(report.Tags, item)
+	}
+	// 汇总覆盖全部 Tag，超出 max_tags 的长尾计入“未分类”
+	report.Unclassified += len(stats.TagStats) - len(tagStats)
+	report.Categories = statistics.RollupCategories(stats.TagStats, assign)
+
+	// 需要复核的 Tag 排在前面，便于人工检查
+	sort.SliceStable(report.Tags, func(i, j int) bool {
+		return report.Tags[i].NeedsReview && !report.Tags[j].NeedsReview
+	})
+	return report, nil
+}
@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"biliTagAnalyse/statistics"
+)
+
+func categoryStats() *statistics.StatsResult {
+	return &statistics.StatsResult{TagStats: []statistics.TagStat{
+		{Tag: "原神", Count: 10},
+		{Tag: "翻唱", Count: 8},
+		{Tag: "日常", Count: 6},
+		{Tag: "旧分类", Count: 4},
+		{Tag: "人工", Count: 3},
+		{Tag: "漏掉", Count: 2},
+		{Tag: "长尾", Count: 1},
+	}}
+}
+
+func TestCategorize(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "categories.json")
+	seed := map[string]CachedCategory{
+		// 缓存命中，不再请求模型
+		"翻唱": {Category: "音乐", Confidence: 0.9, UpdatedAt: time.Now()},
+		// 分类已不在分类表中，需要重新分类
+		"旧分类": {Category: "鬼畜", Confidence: 0.9, UpdatedAt: time.Now()},
+		// 人工确认的分类即使不在分类表中、置信度低也保留且不标记复核
+		"人工": {Category: "自定义", Confidence: 0.1, Manual: true},
+	}
+	if err := saveCategoryCache(cacheFile, seed); err != nil {
+		t.Fatal(err)
+	}
+
+	response := `{"assignments":[
+		{"tag":"原神","category":"游戏","confidence":0.95},
+		{"tag":"日常","category":"生活","confidence":0.4},
+		{"tag":"旧分类","category":"不存在的分类","confidence":0.8},
+		{"tag":"未请求","category":"游戏","confidence":1}
+	]}`
+	provider := &recordingProvider{mockProvider: mockProvider{cfg: MockConfig{Model: "m", Response: response}}}
+	a := &Analyzer{provider: provider, taxonomy: TaxonomyConfig{
+		Categories: []string{"游戏", "音乐", "生活"},
+		CacheFile:  cacheFile,
+		MaxTags:    6,
+	}}
+
+	report, err := a.categorize(context.Background(), categoryStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(provider.prompts) != 1 {
+		t.Fatalf("默认批大小下应只请求一次，实际 %d 次", len(provider.prompts))
+	}
+	prompt := provider.prompts[0]
+	for _, tag := range []string{"原神", "日常", "旧分类", "漏掉"} {
+		if !strings.Contains(prompt, tag) {
+			t.Errorf("提示词应包含待分类的 %s", tag)
+		}
+	}
+	for _, tag := range []string{"翻唱", "人工", "长尾"} {
+		if strings.Contains(prompt, tag) {
+			t.Errorf("提示词不应包含 %s（缓存命中、人工确认或超出 max_tags）", tag)
+		}
+	}
+
+	if report.CacheHits != 2 || report.Classified != 3 || report.Unclassified != 2 || report.NeedsReview != 2 {
+		t.Errorf("分类报告计数不正确: %+v", report)
+	}
+	byTag := make(map[string]TagCategory)
+	for _, item := range report.Tags {
+		byTag[item.Tag] = item
+	}
+	if got := byTag["旧分类"]; got.Category != FallbackCategory || got.Confidence != 0 || !got.NeedsReview {
+		t.Errorf("分类表之外的结果应归入%s并标记复核: %+v", FallbackCategory, got)
+	}
+	if got := byTag["人工"]; got.Category != "自定义" || !got.Manual || got.NeedsReview || !got.Cached {
+		t.Errorf("人工确认的分类应原样保留: %+v", got)
+	}
+	if got := byTag["翻唱"]; !got.Cached || got.NeedsReview {
+		t.Errorf("缓存命中的分类: %+v", got)
+	}
+	if got := byTag["原神"]; got.Cached || got.Category != "游戏" {
+		t.Errorf("新分类的结果: %+v", got)
+	}
+	if _, ok := byTag["未请求"]; ok {
+		t.Error("模型返回的未请求 Tag 应被忽略")
+	}
+	// 需要复核的排在前面
+	if !report.Tags[0].NeedsReview || !report.Tags[1].NeedsReview || report.Tags[2].NeedsReview {
+		t.Errorf("需要复核的 Tag 应排在前面: %+v", report.Tags)
+	}
+
+	// 新结果写回缓存，漏掉的 Tag 不写入，下次运行重试
+	cache, err := LoadCategoryCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache["原神"].Category != "游戏" || cache["原神"].Model != "mock/m" {
+		t.Errorf("缓存中的新结果: %+v", cache["原神"])
+	}
+	if _, ok := cache["漏掉"]; ok {
+		t.Error("模型漏掉的 Tag 不应写入缓存")
+	}
+	if !cache["人工"].Manual {
+		t.Error("人工确认的记录不应被覆盖")
+	}
+
+	// 汇总覆盖全部 Tag，未分类（漏掉的和超出 max_tags 的长尾）计入“未分类”
+	counts := make(map[string]int)
+	for _, c := range report.Categories {
+		counts[c.Category] = c.Count
+	}
+	if counts["游戏"] != 10 || counts["音乐"] != 8 || counts[statistics.UncategorizedLabel] != 3 || counts["自定义"] != 3 {
+		t.Errorf("分类汇总不正确: %+v", report.Categories)
+	}
+}
+
+func TestCategorizeSkipsFailedBatch(t *testing.T) {
+	provider := &recordingProvider{mockProvider: mockProvider{cfg: MockConfig{Model: "m", Response: "不是 JSON"}}}
+	a := &Analyzer{provider: provider, taxonomy: TaxonomyConfig{CacheFile: filepath.Join(t.TempDir(), "categories.json"), BatchSize: 3}}
+
+	report, err := a.categorize(context.Background(), categoryStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provider.prompts) != 3 || report.Classified != 0 || report.Unclassified != 7 {
+		t.Errorf("失败的批次应跳过，Tag 计为未分类: 请求 %d 次，报告 %+v", len(provider.prompts), report)
+	}
+}
+
+func TestTaxonomyDefaults(t *testing.T) {
+	got := TaxonomyConfig{Categories: []string{" 游戏 ", "音乐", "游戏", ""}}.withDefaults()
+	if strings.Join(got.Categories, ",") != "游戏,音乐,"+FallbackCategory {
+		t.Errorf("分类表应去空白、去重并补上%s: %v", FallbackCategory, got.Categories)
+	}
+	if got := (TaxonomyConfig{}).withDefaults(); len(got.Categories) != len(DefaultCategories) || got.MinConfidence != defaultMinConfidence {
+		t.Errorf("零值配置应使用默认值: %+v", got)
+	}
+}
@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"biliTagAnalyse/statistics"
+)
+
+const (
+	defaultEmbeddingCacheFile = "results/tag_embeddings.json"
+	defaultEmbeddingBatchSize = 64
+	defaultClusterMaxTags     = 500
+	defaultClusterThreshold   = 0.85
+)
+
+// ClusterConfig 对应 config.json 中的 clustering 配置块，零值字段使用默认值
+type ClusterConfig struct {
+	Threshold float64 `json:"threshold"`
+	CacheFile string  `json:"cache_file"`
+	BatchSize int     `json:"batch_size"`
+	MaxTags   int     `json:"max_tags"`
+}
+
+func (c ClusterConfig) withDefaults() ClusterConfig {
+	if c.Threshold <= 0 {
+		c.Threshold = defaultClusterThreshold
+	}
+	if c.CacheFile == "" {
+		c.CacheFile = defaultEmbeddingCacheFile
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultEmbeddingBatchSize
+	}
+	if c.MaxTags <= 0 {
+		c.MaxTags = defaultClusterMaxTags
+	}
+	return c
+}
+
+// ClusterReport 是语义聚类结果，写入 analysis_result.json 的 clusters 字段。
+// Merged 为按簇合并后的 Tag 列表，原始列表即 raw_stats.tag_stats。
+type ClusterReport struct {
+	Model     string                  `json:"model"`
+	Threshold float64                 `json:"threshold"`
+	CacheFile string                  `json:"cache_file"`
+	Embedded  int                     `json:"embedded"`
+	CacheHits int                     `json:"cache_hits"`
+	RawTags   int                     `json:"raw_tags"`
+	Clusters  int                     `json:"clusters"`
+	Merged    []statistics.TagCluster `json:"merged"`
+}
+
+// embeddingCache 以 "后端/向量模型" 为第一层键、Tag 为第二层键保存向量，换模型后不会误用旧向量
+type embeddingCache map[string]map[string][]float64
+
+func loadEmbeddingCache(path string) (embeddingCache, error) {
+	cache := make(embeddingCache)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取向量缓存失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("解析向量缓存失败: %w", err)
+	}
+	return cache, nil
+}
+
+func (c embeddingCache) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建向量缓存目录失败: %w", err)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("序列化向量缓存失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("写入向量缓存失败: %w", err)
+	}
+	return nil
+}
+
+// cluster 为出现次数最多的 max_tags 个 Tag 获取向量（优先读缓存，其余按 batch_size 分批请求），
+// 再按余弦相似度阈值聚类。超出 max_tags 的长尾和取向量失败的 Tag 各自单独成簇。
+func (a *Analyzer) cluster(ctx context.Context, stats *statistics.StatsResult) (*ClusterReport, error) {
+	if !a.provider.Capabilities().Embed {
+		return nil, fmt.Errorf("%s 后端不支持文本向量", a.provider.Name())
+	}
+
+	cfg := a.clustering.withDefaults()
+	cache, err := loadEmbeddingCache(cfg.CacheFile)
+	if err != nil {
+		return nil, err
+	}
+
+	model := a.provider.Name() + "/" + a.provider.EmbeddingModel()
+	vectors := cache[model]
+	if vectors == nil {
+		vectors = make(map[string][]float64)
+		cache[model] = vectors
+	}
+
+	tagStats := stats.TagStats
+	if len(tagStats) > cfg.MaxTags {
+		tagStats = tagStats[:cfg.MaxTags]
+	}
+
+	report := &ClusterReport{Model: model, Threshold: cfg.Threshold, CacheFile: cfg.CacheFile, RawTags: len(stats.TagStats)}
+	var pending []string
+	for _, tag := range tagStats {
+		if _, ok := vectors[tag.Tag]; ok {
+			report.CacheHits++
+		} else {
+			pending = append(pending, tag.Tag)
+		}
+	}
+	log.Printf("语义聚类：向量模型 %s，%d 个 Tag，缓存命中 %d 个，需要请求 %d 个", model, len(tagStats), report.CacheHits, len(pending))
+
+	for start := 0; start < len(pending); start += cfg.BatchSize {
+		batch := pending[start:min(start+cfg.BatchSize, len(pending))]
+		embeddings, err := a.provider.Embed(ctx, batch)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Printf("语义聚类已取消，剩余 %d 个 Tag 没有向量", len(pending)-start)
+				break
+			}
+			if errors.Is(err, errEmbedUnavailable) {
+				return nil, err
+			}
+			log.Printf("第 %d 批向量请求失败，跳过: %v", start/cfg.BatchSize+1, err)
+			continue
+		}
+		for i, tag := range batch {
+			vectors[tag] = embeddings[i]
+		}
+		report.Embedded += len(batch)
+		if err := cache.save(cfg.CacheFile); err != nil {
+			return nil, err
+		}
+	}
+
+	input := make([][]float64, len(tagStats))
+	for i, tag := range tagStats {
+		input[i] = vectors[tag.Tag]
+	}
+	// 传入完整 Tag 列表，没有向量的长尾单独成簇，合并视图与原始列表覆盖相同的 Tag
+	report.Merged = statistics.ClusterTags(stats.TagStats, input, cfg.Threshold)
+	for _, c := range report.Merged {
+		if len(c.Members) > 1 {
+			report.Clusters++
+		}
+	}
+	return report, nil
+}
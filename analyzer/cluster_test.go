@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"biliTagAnalyse/cmd"
+	"biliTagAnalyse/statistics"
+)
+
+// embedStub 在 mock 后端基础上返回固定向量，并记录每次向量请求的批次
+type embedStub struct {
+	mockProvider
+	vectors map[string][]float64
+	batches [][]string
+	err     error
+}
+
+func (p *embedStub) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	p.batches = append(p.batches, texts)
+	if p.err != nil {
+		return nil, p.err
+	}
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		out[i] = p.vectors[text]
+	}
+	return out, nil
+}
+
+func clusterStats() *statistics.StatsResult {
+	return &statistics.StatsResult{TagStats: []statistics.TagStat{
+		{Tag: "我的世界", Count: 10},
+		{Tag: "音乐", Count: 8},
+		{Tag: "MC", Count: 5},
+		{Tag: "minecraft", Count: 3},
+		{Tag: "长尾", Count: 1},
+	}}
+}
+
+func newEmbedStub() *embedStub {
+	return &embedStub{
+		mockProvider: mockProvider{cfg: MockConfig{Model: "stub"}},
+		vectors: map[string][]float64{
+			"我的世界":      {1, 0},
+			"音乐":        {0, 1},
+			"MC":        {1, 0.05},
+			"minecraft": {0.98, 0.1},
+			"长尾":        {0.7, 0.7},
+		},
+	}
+}
+
+func TestClusterBatchesAndCache(t *testing.T) {
+	stub := newEmbedStub()
+	cfg := ClusterConfig{Threshold: 0.9, CacheFile: filepath.Join(t.TempDir(), "vectors.json"), BatchSize: 2, MaxTags: 4}
+	a := &Analyzer{provider: stub, clustering: cfg}
+
+	report, err := a.cluster(context.Background(), clusterStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只为前 max_tags 个 Tag 请求向量，每批不超过 batch_size 个
+	if len(stub.batches) != 2 || len(stub.batches[0]) != 2 || len(stub.batches[1]) != 2 {
+		t.Fatalf("向量请求批次 = %v", stub.batches)
+	}
+	if report.Embedded != 4 || report.CacheHits != 0 || report.RawTags != 5 || report.Clusters != 1 {
+		t.Errorf("聚类报告不正确: %+v", report)
+	}
+	// 超出 max_tags 的长尾单独成簇，合并视图仍覆盖全部 Tag
+	if len(report.Merged) != 3 || report.Merged[0].Count != 18 || report.Merged[2].Label != "长尾" {
+		t.Errorf("合并结果不正确: %+v", report.Merged)
+	}
+
+	// 第二次运行全部命中缓存，不再请求
+	stub.batches = nil
+	report, err = a.cluster(context.Background(), clusterStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.batches) != 0 || report.CacheHits != 4 || report.Embedded != 0 {
+		t.Errorf("应全部命中向量缓存: 批次 %v，报告 %+v", stub.batches, report)
+	}
+
+	// 缓存按 后端/向量模型 区分，换模型后重新请求
+	stub.cfg.Model = "other"
+	stub.batches = nil
+	if _, err := a.cluster(context.Background(), clusterStats()); err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.batches) != 2 {
+		t.Errorf("换向量模型后不应复用旧向量: %v", stub.batches)
+	}
+}
+
+func TestClusterBatchErrors(t *testing.T) {
+	cfg := ClusterConfig{CacheFile: filepath.Join(t.TempDir(), "vectors.json"), BatchSize: 2}
+
+	// 单批失败只跳过该批，对应的 Tag 单独成簇
+	stub := newEmbedStub()
+	stub.err = fmt.Errorf("503")
+	report, err := (&Analyzer{provider: stub, clustering: cfg}).cluster(context.Background(), clusterStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.batches) != 3 || report.Embedded != 0 || len(report.Merged) != 5 {
+		t.Errorf("请求失败的批次应被跳过: 批次 %v，报告 %+v", stub.batches, report)
+	}
+
+	// 向量接口不可用时立即报错，不再逐批重试
+	stub = newEmbedStub()
+	stub.err = fmt.Errorf("%w: 缺少 embeddings_endpoint", errEmbedUnavailable)
+	if _, err := (&Analyzer{provider: stub, clustering: cfg}).cluster(context.Background(), clusterStats()); err == nil || len(stub.batches) != 1 {
+		t.Errorf("向量接口不可用时应报错，实际 %v，批次 %v", err, stub.batches)
+	}
+}
+
+func TestClusterRequiresEmbedCapability(t *testing.T) {
+	provider, err := newAnthropicProvider(nil, &cmd.Options{APIKey: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Analyzer{provider: provider, clustering: ClusterConfig{CacheFile: filepath.Join(t.TempDir(), "vectors.json")}}
+	if _, err := a.cluster(context.Background(), clusterStats()); err == nil {
+		t.Error("不支持向量的后端应报错")
+	}
+}
@@ -0,0 +1,240 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"biliTagAnalyse/cmd"
+)
+
+const (
+	defaultGeminiEndpoint       = "https://generativelanguage.googleapis.com/v1beta"
+	defaultGeminiModel          = "gemini-1.5-flash"
+	defaultGeminiEmbeddingModel = "text-embedding-004"
+)
+
+func init() {
+	RegisterProvider("gemini", newGeminiProvider)
+}
+
+// GeminiConfig 对应 Google generateContent 接口，Endpoint 为 API 根地址（不含 /models/...）
+type GeminiConfig struct {
+	Endpoint       string `json:"endpoint"`
+	APIKey         string `json:"api_key"`
+	Model          string `json:"model"`
+	EmbeddingModel string `json:"embedding_model"`
+	cmd.GenerationParams
+}
+
+type geminiProvider struct {
+	cfg GeminiConfig
+}
+
+func newGeminiProvider(raw json.RawMessage, opts *cmd.Options) (Provider, error) {
+	var cfg GeminiConfig
+	if err := decodeProviderConfig(raw, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = explicitEndpoint(opts, "gemini")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = defaultGeminiEndpoint
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = opts.APIKey
+	}
+	if cfg.Model == "" {
+		cfg.Model = opts.APIModel
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaultGeminiModel
+	}
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = defaultGeminiEmbeddingModel
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("Gemini 接口需要指定 -api-key")
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.GenerationParams.Fill(opts.Generation)
+	return &geminiProvider{cfg: cfg}, nil
+}
+
+func (p *geminiProvider) Name() string  { return "gemini" }
+func (p *geminiProvider) Model() string { return p.cfg.Model }
+
+func (p *geminiProvider) EmbeddingModel() string { return p.cfg.EmbeddingModel }
+
+func (p *geminiProvider) CacheScope() string { return p.cfg.Endpoint }
+
+func (p *geminiProvider) Params() cmd.GenerationParams { return p.cfg.GenerationParams }
+
+func (p *geminiProvider) Capabilities() Capabilities {
+	return Capabilities{Chat: true, Embed: true, JSONSchema: true, JSONMode: true}
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"topP,omitempty"`
+	MaxOutputTokens  *int     `json:"maxOutputTokens,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	StopSequences    []string `json:"stopSequences,omitempty"`
+	ResponseMimeType string   `json:"responseMimeType,omitempty"`
+	ResponseSchema   any      `json:"responseSchema,omitempty"`
+}
+
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback,omitempty"`
+}
+
+type geminiEmbedRequest struct {
+	Requests []geminiEmbedItem `json:"requests"`
+}
+
+type geminiEmbedItem struct {
+	Model   string        `json:"model"`
+	Content geminiContent `json:"content"`
+}
+
+type geminiEmbedResponse struct {
+	Embeddings []struct {
+		Values []float64 `json:"values"`
+	} `json:"embeddings"`
+}
+
+func (p *geminiProvider) headers() map[string]string {
+	return map[string]string{"x-goog-api-key": p.cfg.APIKey}
+}
+
+func (p *geminiProvider) modelURL(model, method string) string {
+	return fmt.Sprintf("%s/models/%s:%s", p.cfg.Endpoint, model, method)
+}
+
+func (p *geminiProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	return p.Chat(ctx, []Message{{Role: "user", Content: prompt}}, opts)
+}
+
+// Chat 将 assistant 角色映射为 Gemini 的 model 角色，system 消息放入 systemInstruction
+func (p *geminiProvider) Chat(ctx context.Context, messages []Message, opts GenerateOptions) (string, error) {
+	system, rest := splitSystem(messages)
+
+	reqBody := geminiRequest{
+		GenerationConfig: &geminiGenerationConfig{
+			Temperature:     p.cfg.Temperature,
+			TopP:            p.cfg.TopP,
+			MaxOutputTokens: p.cfg.MaxTokens,
+			Seed:            p.cfg.Seed,
+			StopSequences:   p.cfg.Stop,
+		},
+	}
+	if system != "" {
+		reqBody.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: system}}}
+	}
+	for _, m := range rest {
+		role := m.Role
+		if role == "assistant" {
+			role = "model"
+		}
+		reqBody.Contents = append(reqBody.Contents, geminiContent{Role: role, Parts: []geminiPart{{Text: m.Content}}})
+	}
+	if opts.Schema != nil {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseSchema = opts.Schema
+	}
+
+	url := p.modelURL(p.cfg.Model, "generateContent")
+	log.Printf("正在调用Gemini API: %s", url)
+	body, err := postJSON(ctx, url, p.headers(), reqBody, geminiError)
+	if err != nil {
+		return "", fmt.Errorf("请求Gemini失败: %w", err)
+	}
+
+	var resp geminiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		return "", fmt.Errorf("Gemini拒绝了请求: %s", resp.PromptFeedback.BlockReason)
+	}
+	if len(resp.Candidates) == 0 {
+		return "", fmt.Errorf("Gemini返回空响应")
+	}
+
+	candidate := resp.Candidates[0]
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("Gemini返回空响应 (finishReason: %s)", candidate.FinishReason)
+	}
+	return text.String(), nil
+}
+
+func (p *geminiProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	model := "models/" + p.cfg.EmbeddingModel
+	reqBody := geminiEmbedRequest{}
+	for _, text := range texts {
+		reqBody.Requests = append(reqBody.Requests, geminiEmbedItem{
+			Model:   model,
+			Content: geminiContent{Parts: []geminiPart{{Text: text}}},
+		})
+	}
+
+	body, err := postJSON(ctx, p.modelURL(p.cfg.EmbeddingModel, "batchEmbedContents"), p.headers(), reqBody, geminiError)
+	if err != nil {
+		return nil, fmt.Errorf("请求Gemini向量失败: %w", err)
+	}
+
+	var resp geminiEmbedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("Gemini返回 %d 个向量，期望 %d 个", len(resp.Embeddings), len(texts))
+	}
+	vectors := make([][]float64, len(texts))
+	for i, e := range resp.Embeddings {
+		vectors[i] = e.Values
+	}
+	return vectors, nil
+}
+
+// geminiError 解析 {"error":{"code":400,"message":"...","status":"INVALID_ARGUMENT"}}
+func geminiError(status int, body []byte) error {
+	var resp struct {
+		Error *struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Error != nil && resp.Error.Message != "" {
+		return fmt.Errorf("Gemini返回错误状态码 %d (%s): %s", status, resp.Error.Status, resp.Error.Message)
+	}
+	return fmt.Errorf("Gemini返回错误状态码 %d: %s", status, string(body))
+}
@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"biliTagAnalyse/cmd"
+)
+
+func TestGeminiEndpointFallback(t *testing.T) {
+	opts := cmd.Options{APIKey: "k", Provider: "gemini", APIEndpoint: "https://api.openai.com/v1/chat/completions"}
+	provider, err := newGeminiProvider(nil, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := provider.(*geminiProvider).cfg.Endpoint; got != defaultGeminiEndpoint {
+		t.Errorf("未通过 -api-format gemini 指定时应使用官方地址，实际 %s", got)
+	}
+
+	opts.APIFormat = "gemini"
+	opts.APIEndpoint = "https://proxy.example.com/v1beta/"
+	provider, err = newGeminiProvider(nil, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := provider.(*geminiProvider).cfg.Endpoint; got != "https://proxy.example.com/v1beta" {
+		t.Errorf("endpoint = %s，期望去掉结尾斜杠的 -api-endpoint", got)
+	}
+}
+
+func TestGeminiChat(t *testing.T) {
+	var got geminiRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-test:generateContent" {
+			t.Errorf("请求路径 = %s", r.URL.Path)
+		}
+		if r.Header.Get("x-goog-api-key") != "AIza-test" {
+			t.Errorf("x-goog-api-key = %q", r.Header.Get("x-goog-api-key"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("请求体不是合法 JSON: %v", err)
+		}
+		io.WriteString(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"{\"summary\":"},{"text":"\"ok\"}"}]},"finishReason":"STOP"}]}`)
+	}))
+	defer server.Close()
+
+	opts := cmd.Options{APIKey: "AIza-test", APIModel: "gemini-test", APIFormat: "gemini", APIEndpoint: server.URL}
+	provider, err := newGeminiProvider(nil, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := map[string]any{"type": "object"}
+	text, err := provider.Chat(context.Background(), []Message{
+		{Role: "system", Content: "你是分析师"},
+		{Role: "user", Content: "分析"},
+		{Role: "assistant", Content: "好的"},
+		{Role: "user", Content: "继续"},
+	}, GenerateOptions{Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != `{"summary":"ok"}` {
+		t.Errorf("回答 = %q", text)
+	}
+
+	if got.SystemInstruction == nil || got.SystemInstruction.Parts[0].Text != "你是分析师" {
+		t.Errorf("system 消息应放入 systemInstruction: %+v", got.SystemInstruction)
+	}
+	roles := make([]string, len(got.Contents))
+	for i, c := range got.Contents {
+		roles[i] = c.Role
+	}
+	if strings.Join(roles, ",") != "user,model,user" {
+		t.Errorf("assistant 应映射为 model，实际角色 %v", roles)
+	}
+	if got.GenerationConfig.ResponseMimeType != "application/json" || got.GenerationConfig.ResponseSchema == nil {
+		t.Errorf("结构化输出应设置 responseMimeType 和 responseSchema: %+v", got.GenerationConfig)
+	}
+}
+
+func TestGeminiBlockedPrompt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"promptFeedback":{"blockReason":"SAFETY"}}`)
+	}))
+	defer server.Close()
+
+	opts := cmd.Options{APIKey: "k", APIFormat: "gemini", APIEndpoint: server.URL}
+	provider, err := newGeminiProvider(nil, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Complete(context.Background(), "分析", GenerateOptions{}); err == nil || !strings.Contains(err.Error(), "SAFETY") {
+		t.Fatalf("应返回拒绝原因，实际: %v", err)
+	}
+}
+
+func TestGeminiErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`)
+	}))
+	defer server.Close()
+
+	opts := cmd.Options{APIKey: "k", APIFormat: "gemini", APIEndpoint: server.URL}
+	provider, err := newGeminiProvider(nil, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.Complete(context.Background(), "分析", GenerateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "400 (INVALID_ARGUMENT): API key not valid") {
+		t.Fatalf("错误信息应包含状态码、状态和说明，实际: %v", err)
+	}
+}
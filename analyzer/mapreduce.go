@@ -0,0 +1,305 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"biliTagAnalyse/crawler"
+	"biliTagAnalyse/statistics"
+)
+
+const (
+	defaultContextWindow = 8192
+	// defaultOutputReserve 是未设置 max_tokens 时为模型回答预留的 token 数
+	defaultOutputReserve = 1024
+	// minChunkBudget 是每块数据至少可用的 token 数，低于该值说明上下文窗口太小
+	minChunkBudget = 256
+	// cooccurPartners 是每个 Tag 在分块数据中列出的同现 Tag 数
+	cooccurPartners = 3
+)
+
+// MapReduceInfo 记录分块分析的规模，写入 analysis_result.json
+type MapReduceInfo struct {
+	ContextWindow int `json:"context_window"`
+	ChunkBudget   int `json:"chunk_budget"`
+	Chunks        int `json:"chunks"`
+	TagsCovered   int `json:"tags_covered"`
+	TitlesCovered int `json:"titles_covered,omitempty"`
+	ReduceRounds  int `json:"reduce_rounds"`
+}
+
+// dataChunk 是 map 阶段的一块输入，kind 决定提示词中的数据说明
+type dataChunk struct {
+	kind  string
+	lines []string
+}
+
+const (
+	chunkTags   = "tags"
+	chunkTitles = "titles"
+)
+
+// estimateTokens 粗略估算 token 数：没有分词器，按中日韩字符约 1 token、其余约 4 个字符 1 token 计算
+func estimateTokens(s string) int {
+	cjk, other := 0, 0
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
+
+// contextWindow 返回用于计算分块大小的上下文窗口
+func (a *Analyzer) contextWindow() int {
+	if a.opts.ContextWindow > 0 {
+		return a.opts.ContextWindow
+	}
+	return defaultContextWindow
+}
+
+// outputReserve 返回为模型回答预留的 token 数
+func (a *Analyzer) outputReserve() int {
+	if params := a.provider.Params(); params.MaxTokens != nil {
+		return *params.MaxTokens
+	}
+	return defaultOutputReserve
+}
+
+// tagLines 将完整 Tag 列表格式化为每行一个 Tag，附带出现次数、覆盖视频占比和最常同现的 Tag
+func tagLines(stats *statistics.StatsResult) []string {
+	partners := make(map[string][]string)
+	for _, pair := range stats.CoOccurrence {
+		if len(partners[pair.A]) < cooccurPartners {
+			partners[pair.A] = append(partners[pair.A], pair.B)
+		}
+		if len(partners[pair.B]) < cooccurPartners {
+			partners[pair.B] = append(partners[pair.B], pair.A)
+		}
+	}
+
+	lines := make([]string, 0, len(stats.TagStats))
+	for _, tag := range stats.TagStats {
+		line := fmt.Sprintf("%s | %d 次", tag.Tag, tag.Count)
+		if tag.DocFreq > 0 && stats.TotalVideos > 0 {
+			line += fmt.Sprintf(" | %.1f%%", float64(tag.DocFreq)/float64(stats.TotalVideos)*100)
+		}
+		if p := partners[tag.Tag]; len(p) > 0 {
+			line += " | 常与 " + strings.Join(p, "、") + " 同现"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// titleLines 将视频标题和 Tag 格式化为每行一个视频，多轮中重复出现的视频只保留一次
+func titleLines(videos []*crawler.VideoInfo) []string {
+	seen := make(map[string]bool)
+	lines := make([]string, 0, len(videos))
+	for _, v := range videos {
+		if v.Title == "" || seen[v.Link] {
+			continue
+		}
+		seen[v.Link] = true
+		lines = append(lines, fmt.Sprintf("《%s》 [%s]", v.Title, strings.Join(v.Tags, ", ")))
+	}
+	return lines
+}
+
+// chunkLines 按 token 预算贪心分块，单行超出预算时独占一块
+func chunkLines(kind string, lines []string, budget int) []dataChunk {
+	var chunks []dataChunk
+	var current []string
+	used := 0
+	for _, line := range lines {
+		cost := estimateTokens(line) + 1
+		if used+cost > budget && len(current) > 0 {
+			chunks = append(chunks, dataChunk{kind: kind, lines: current})
+			current, used = nil, 0
+		}
+		current = append(current, line)
+		used += cost
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, dataChunk{kind: kind, lines: current})
+	}
+	return chunks
+}
+
+func overviewText(stats *statistics.StatsResult) string {
+	return fmt.Sprintf("爬取时间: %s，总视频数: %d，不同Tag数: %d", stats.CrawlTime, stats.TotalVideos, stats.TotalTags)
+}
+
+func (a *Analyzer) buildMapPrompt(stats *statistics.StatsResult, chunk dataChunk, index, total int) string {
+	description := "每行一个 Tag，格式：Tag | 出现次数 | 覆盖视频占比 | 常同时出现的 Tag。列表按出现次数从高到低排列，靠后的是长尾 Tag。"
+	if chunk.kind == chunkTitles {
+		description = "每行一个视频，格式：《标题》 [该视频的 Tag]。"
+	}
+
+	return fmt.Sprintf(`你是一个B站视频内容分析专家。B站推荐视频数据量较大，已分成 %d 块分别分析，这是第 %d 块。
+
+整体统计：%s
+
+%s
+%s
+
+请总结这一块数据反映的内容主题、用户偏好和值得注意的 Tag（包括长尾 Tag 和经常同现的 Tag 组合），这些小结之后会被合并成整体分析。
+请用中文回答，保持简洁专业。
+`+structuredInstruction,
+		total,
+		index,
+		overviewText(stats),
+		description,
+		strings.Join(chunk.lines, "\n"),
+		insightTagCount,
+	)
+}
+
+// summaryText 将一份小结格式化为 reduce 阶段的输入
+func summaryText(index int, s *StructuredAnalysis) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "【小结 %d】%s\n", index, s.Summary)
+	if len(s.Trends) > 0 {
+		fmt.Fprintf(&b, "趋势：%s\n", strings.Join(s.Trends, "；"))
+	}
+	if len(s.Suggestions) > 0 {
+		fmt.Fprintf(&b, "建议：%s\n", strings.Join(s.Suggestions, "；"))
+	}
+	for _, insight := range s.TagInsights {
+		fmt.Fprintf(&b, "- %s：%s\n", insight.Tag, insight.Description)
+	}
+	return b.String()
+}
+
+// buildReducePrompt 生成中间轮次的合并提示词
+func (a *Analyzer) buildReducePrompt(stats *statistics.StatsResult, summaries []string) string {
+	return fmt.Sprintf(`你是一个B站视频内容分析专家。以下是对B站推荐视频数据分块分析得到的 %d 份小结。
+
+整体统计：%s
+
+%s
+
+请将这些小结合并为一份更精炼的小结，保留重要的趋势、建议和 Tag 描述，之后还会继续合并。
+请用中文回答，保持简洁专业。
+`+structuredInstruction,
+		len(summaries),
+		overviewText(stats),
+		strings.Join(summaries, "\n"),
+		insightTagCount,
+	)
+}
+
+// buildFinalPrompt 在 -prompt 模板的基础上附加各块小结，作为最终合并的提示词
+func buildFinalPrompt(base string, summaries []string) string {
+	return withInstruction(fmt.Sprintf(`%s
+
+补充：完整 Tag 列表（含长尾 Tag 和同现关系）已分成多块分析，以下是合并得到的 %d 份小结，请结合上面的数据和这些小结作答，tag_insights 也可以包含小结中提到的重要长尾 Tag。
+
+%s`, base, len(summaries), strings.Join(summaries, "\n")))
+}
+
+// chunkBudget 计算每块数据可用的 token 数：上下文窗口减去回答预留和提示词本身的开销
+func (a *Analyzer) chunkBudget(overhead string) (int, error) {
+	budget := a.contextWindow() - a.outputReserve() - estimateTokens(overhead)
+	if budget < minChunkBudget {
+		return 0, fmt.Errorf("上下文窗口 %d 过小：预留回答 %d token 后每块只剩 %d token，请调大 context_window 或调小 max_tokens",
+			a.contextWindow(), a.outputReserve(), budget)
+	}
+	return budget, nil
+}
+
+// analyzeMapReduce 将完整 Tag 列表（以及可选的视频标题）按上下文窗口分块，逐块生成小结（map），
+// 再把小结合并为最终分析（reduce）；小结总量超出窗口时分组多轮合并，最终合并使用 -prompt 模板。
+func (a *Analyzer) analyzeMapReduce(ctx context.Context, stats *statistics.StatsResult, prompt *PromptRecord) (*AnalysisResult, error) {
+	mapBudget, err := a.chunkBudget(a.buildMapPrompt(stats, dataChunk{kind: chunkTags}, 1, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := chunkLines(chunkTags, tagLines(stats), mapBudget)
+	titles := 0
+	if a.opts.WithTitles {
+		lines := titleLines(a.videos)
+		titles = len(lines)
+		chunks = append(chunks, chunkLines(chunkTitles, lines, mapBudget)...)
+	}
+
+	info := &MapReduceInfo{
+		ContextWindow: a.contextWindow(),
+		ChunkBudget:   mapBudget,
+		Chunks:        len(chunks),
+		TagsCovered:   len(stats.TagStats),
+		TitlesCovered: titles,
+	}
+	log.Printf("分块分析：上下文窗口 %d token，每块约 %d token，共 %d 块（%d 个 Tag，%d 个视频标题）",
+		info.ContextWindow, info.ChunkBudget, info.Chunks, info.TagsCovered, info.TitlesCovered)
+
+	var summaries []string
+	for i, chunk := range chunks {
+		log.Printf("Map 阶段：第 %d/%d 块（%d 行）", i+1, len(chunks), len(chunk.lines))
+		response, err := a.generate(ctx, a.buildMapPrompt(stats, chunk, i+1, len(chunks)), false)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 块分析失败: %w", i+1, err)
+		}
+		summaries = append(summaries, a.summarize(len(summaries)+1, response))
+	}
+
+	// 最终合并与中间合并的提示词开销不同，分别计算：前者决定何时可以直接最终合并，后者决定每组的大小
+	finalBudget, err := a.chunkBudget(buildFinalPrompt(prompt.Rendered, nil))
+	if err != nil {
+		return nil, err
+	}
+	reduceBudget, err := a.chunkBudget(a.buildReducePrompt(stats, nil))
+	if err != nil {
+		return nil, err
+	}
+	// 每轮至少把两份小结合并成一份，小结数严格减少，循环必然结束
+	for len(summaries) > 1 && estimateTokens(strings.Join(summaries, "\n")) > finalBudget {
+		info.ReduceRounds++
+		groups := chunkLines("", summaries, reduceBudget)
+		if len(groups) == len(summaries) {
+			// 每份小结都独占一组时无法继续合并，直接交给最终合并
+			break
+		}
+		log.Printf("Reduce 阶段：第 %d 轮，%d 份小结分为 %d 组合并", info.ReduceRounds, len(summaries), len(groups))
+
+		var merged []string
+		for i, group := range groups {
+			response, err := a.generate(ctx, a.buildReducePrompt(stats, group.lines), false)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 轮第 %d 组合并失败: %w", info.ReduceRounds, i+1, err)
+			}
+			merged = append(merged, a.summarize(len(merged)+1, response))
+		}
+		summaries = merged
+	}
+
+	info.ReduceRounds++
+	log.Printf("Reduce 阶段：合并 %d 份小结为最终分析", len(summaries))
+	prompt.Rendered = buildFinalPrompt(prompt.Rendered, summaries)
+	response, err := a.generate(ctx, prompt.Rendered, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := a.newResult(stats)
+	result.MapReduce = info
+	result.Prompt = prompt
+	applyStructured(result, stats, a.structuredResponse(ctx, response))
+	return result, nil
+}
+
+// summarize 解析一份 map/reduce 回答，无法解析时把原文当作小结
+func (a *Analyzer) summarize(index int, response string) string {
+	parsed, err := ParseStructured(response)
+	if err != nil {
+		log.Printf("小结 %d 不是合法的 JSON，按原文合并: %v", index, err)
+		return fmt.Sprintf("【小结 %d】%s\n", index, strings.TrimSpace(response))
+	}
+	return summaryText(index, parsed)
+}
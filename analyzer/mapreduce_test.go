@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"biliTagAnalyse/cmd"
+	"biliTagAnalyse/statistics"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := map[string]int{
+		"":         0,
+		"游戏":       2,
+		"abcd":     1,
+		"abcde":    2,
+		"原神 | 3 次": 3 + 2, // 3 个汉字，" | 3 " 共 6 个字符
+	}
+	for in, want := range tests {
+		if got := estimateTokens(in); got != want {
+			t.Errorf("estimateTokens(%q) = %d，期望 %d", in, got, want)
+		}
+	}
+}
+
+func TestChunkLinesRespectsBudget(t *testing.T) {
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, fmt.Sprintf("标签%d | %d 次", i, 100-i))
+	}
+	lines = append(lines, strings.Repeat("超长", 40))
+
+	const budget = 40
+	chunks := chunkLines(chunkTags, lines, budget)
+
+	var joined []string
+	for i, chunk := range chunks {
+		cost := 0
+		for _, line := range chunk.lines {
+			cost += estimateTokens(line) + 1
+		}
+		if cost > budget && len(chunk.lines) > 1 {
+			t.Errorf("第 %d 块 %d token 超出预算 %d", i, cost, budget)
+		}
+		if chunk.kind != chunkTags {
+			t.Errorf("第 %d 块类型 = %q", i, chunk.kind)
+		}
+		joined = append(joined, chunk.lines...)
+	}
+	// 超出预算的单行独占一块，分块不丢行也不改变顺序
+	if last := chunks[len(chunks)-1]; len(last.lines) != 1 || last.lines[0] != lines[len(lines)-1] {
+		t.Errorf("超长行应独占最后一块: %v", last.lines)
+	}
+	if !reflect.DeepEqual(joined, lines) {
+		t.Error("分块后的行与输入不一致")
+	}
+	if chunkLines(chunkTags, nil, budget) != nil {
+		t.Error("空输入不应产生分块")
+	}
+}
+
+func TestChunkBudgetTooSmall(t *testing.T) {
+	a := &Analyzer{opts: &cmd.Options{ContextWindow: 1024}, provider: &mockProvider{}}
+	if _, err := a.chunkBudget(strings.Repeat("字", 100)); err == nil {
+		t.Error("预留回答后剩余不足 minChunkBudget 时应报错")
+	}
+	a.opts.ContextWindow = 4096
+	budget, err := a.chunkBudget(strings.Repeat("字", 100))
+	if err != nil || budget != 4096-defaultOutputReserve-100 {
+		t.Errorf("chunkBudget = %d, %v", budget, err)
+	}
+}
+
+// recordingProvider 返回固定的小结并记录每次请求的提示词
+type recordingProvider struct {
+	mockProvider
+	prompts []string
+}
+
+func (p *recordingProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	return p.cfg.Response, nil
+}
+
+func TestMapReduceFitsContextWindow(t *testing.T) {
+	stats := &statistics.StatsResult{TotalVideos: 500}
+	for i := 0; i < 400; i++ {
+		stats.TagStats = append(stats.TagStats, statistics.TagStat{Tag: fmt.Sprintf("长尾标签%03d", i), Count: 400 - i, DocFreq: 1})
+	}
+	stats.TotalTags = len(stats.TagStats)
+
+	// 每份小结都较长，需要多轮合并才能放进最终提示词
+	summary := fmt.Sprintf(`{"summary":"%s","trends":["趋势"],"suggestions":["建议"]}`, strings.Repeat("小结内容", 60))
+	provider := &recordingProvider{mockProvider: mockProvider{cfg: MockConfig{Model: "m", Response: summary}}}
+	a := &Analyzer{opts: &cmd.Options{ContextWindow: 2048, MapReduce: true}, provider: provider}
+
+	result, err := a.analyzeMapReduce(context.Background(), stats, &PromptRecord{Rendered: "请分析以下数据"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := result.MapReduce
+	if info.Chunks < 2 || info.ReduceRounds < 2 || info.TagsCovered != 400 {
+		t.Fatalf("分块信息 = %+v", info)
+	}
+	if want := info.Chunks + 1; len(provider.prompts) <= want {
+		t.Errorf("应有中间合并请求：共 %d 次请求，%d 块", len(provider.prompts), info.Chunks)
+	}
+
+	limit := a.contextWindow() - a.outputReserve()
+	for i, prompt := range provider.prompts {
+		if tokens := estimateTokens(prompt); tokens > limit {
+			t.Errorf("第 %d 次请求的提示词 %d token，超出可用的 %d token", i+1, tokens, limit)
+		}
+	}
+	if result.Summary == "" {
+		t.Error("最终合并结果应有摘要")
+	}
+}
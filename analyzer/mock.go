@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+
+	"biliTagAnalyse/cmd"
+)
+
+func init() {
+	RegisterProvider("mock", newMockProvider)
+}
+
+// MockConfig 配置离线测试用的后端：Response 为空时返回固定的结构化结果
+type MockConfig struct {
+	Model      string `json:"model"`
+	Response   string `json:"response"`
+	Dimensions int    `json:"dimensions"`
+	cmd.GenerationParams
+}
+
+const mockResponse = `{"summary":"这是 mock 后端生成的示例分析，用于在没有模型服务时验证流程。","trends":["示例趋势"],"suggestions":["示例建议"],"tag_insights":[]}`
+
+type mockProvider struct {
+	cfg MockConfig
+}
+
+func newMockProvider(raw json.RawMessage, opts *cmd.Options) (Provider, error) {
+	cfg := MockConfig{Model: "mock", Response: mockResponse, Dimensions: 16}
+	if err := decodeProviderConfig(raw, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Dimensions <= 0 {
+		cfg.Dimensions = 16
+	}
+	cfg.GenerationParams.Fill(opts.Generation)
+	return &mockProvider{cfg: cfg}, nil
+}
+
+func (p *mockProvider) Name() string  { return "mock" }
+func (p *mockProvider) Model() string { return p.cfg.Model }
+
+func (p *mockProvider) EmbeddingModel() string { return p.cfg.Model }
+
+func (p *mockProvider) CacheScope() string { return p.cfg.Response }
+
+func (p *mockProvider) Params() cmd.GenerationParams { return p.cfg.GenerationParams }
+
+func (p *mockProvider) Capabilities() Capabilities {
+	return Capabilities{Chat: true, Stream: true, Embed: true, JSONSchema: true, JSONMode: true}
+}
+
+func (p *mockProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	return p.respond(ctx, opts)
+}
+
+func (p *mockProvider) Chat(ctx context.Context, messages []Message, opts GenerateOptions) (string, error) {
+	return p.respond(ctx, opts)
+}
+
+// respond 在流式模式下按每 8 个字符一段回调，模拟逐段到达的输出
+func (p *mockProvider) respond(ctx context.Context, opts GenerateOptions) (string, error) {
+	if opts.OnToken != nil {
+		runes := []rune(p.cfg.Response)
+		for i := 0; i < len(runes); i += 8 {
+			if err := ctx.Err(); err != nil {
+				return string(runes[:i]), err
+			}
+			opts.OnToken(string(runes[i:min(i+8, len(runes))]))
+		}
+	}
+	return p.cfg.Response, ctx.Err()
+}
+
+// Embed 按文本哈希生成确定性的单位向量，相同文本总是得到相同结果
+func (p *mockProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		h := fnv.New64a()
+		h.Write([]byte(text))
+		seed := h.Sum64()
+
+		v := make([]float64, p.cfg.Dimensions)
+		norm := 0.0
+		for j := range v {
+			seed = seed*6364136223846793005 + 1442695040888963407
+			v[j] = float64(int64(seed>>11))/float64(1<<52) - 1
+			norm += v[j] * v[j]
+		}
+		norm = math.Sqrt(norm)
+		for j := range v {
+			v[j] /= norm
+		}
+		vectors[i] = v
+	}
+	return vectors, ctx.Err()
+}
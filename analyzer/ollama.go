@@ -0,0 +1,251 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"biliTagAnalyse/cmd"
+)
+
+func init() {
+	RegisterProvider("ollama", newOllamaProvider)
+}
+
+type OllamaConfig struct {
+	URL   string `json:"url"`
+	Model string `json:"model"`
+	// EmbeddingModel 为 /api/embed 使用的模型（如 nomic-embed-text），为空时使用 Model
+	EmbeddingModel string `json:"embedding_model"`
+	// ContextWindow 非 0 时作为 options.num_ctx 发送，否则使用模型默认的上下文长度
+	ContextWindow int `json:"context_window"`
+	cmd.GenerationParams
+}
+
+type ollamaProvider struct {
+	cfg OllamaConfig
+}
+
+func newOllamaProvider(raw json.RawMessage, opts *cmd.Options) (Provider, error) {
+	var cfg OllamaConfig
+	if err := decodeProviderConfig(raw, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.URL == "" {
+		cfg.URL = opts.OllamaURL
+	}
+	if cfg.Model == "" {
+		cfg.Model = opts.OllamaModel
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf(cmd.ErrOllamaURL)
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf(cmd.ErrOllamaModel)
+	}
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = cfg.Model
+	}
+	if cfg.ContextWindow == 0 {
+		cfg.ContextWindow = opts.ContextWindow
+	}
+	cfg.GenerationParams.Fill(opts.Generation)
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	return &ollamaProvider{cfg: cfg}, nil
+}
+
+func (p *ollamaProvider) Name() string  { return "ollama" }
+func (p *ollamaProvider) Model() string { return p.cfg.Model }
+
+func (p *ollamaProvider) EmbeddingModel() string { return p.cfg.EmbeddingModel }
+
+// CacheScope 包含服务地址和 num_ctx，上下文长度不同时长提示词会被截断成不同的内容
+func (p *ollamaProvider) CacheScope() string {
+	return fmt.Sprintf("%s num_ctx=%d", p.cfg.URL, p.cfg.ContextWindow)
+}
+
+func (p *ollamaProvider) Params() cmd.GenerationParams { return p.cfg.GenerationParams }
+
+func (p *ollamaProvider) Capabilities() Capabilities {
+	return Capabilities{Chat: true, Stream: true, Embed: true, JSONSchema: true, JSONMode: true}
+}
+
+type OllamaRequest struct {
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Stream  bool           `json:"stream"`
+	Format  any            `json:"format,omitempty"`
+	Options *OllamaOptions `json:"options,omitempty"`
+}
+
+// OllamaOptions 对应 Ollama 请求中的 options，max_tokens 在 Ollama 中称为 num_predict
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+	NumCtx      int      `json:"num_ctx,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+type OllamaResponse struct {
+	Model     string `json:"model"`
+	CreatedAt string `json:"created_at"`
+	Response  string `json:"response"`
+	Done      bool   `json:"done"`
+}
+
+type ollamaChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Format   any            `json:"format,omitempty"`
+	Options  *OllamaOptions `json:"options,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Message Message `json:"message"`
+	Done    bool    `json:"done"`
+}
+
+// ollamaStreamChunk 是流式响应中的一行 NDJSON，同时覆盖 /api/generate、/api/chat 和出错时的 error 字段
+type ollamaStreamChunk struct {
+	Response string  `json:"response"`
+	Message  Message `json:"message"`
+	Done     bool    `json:"done"`
+	Error    string  `json:"error"`
+}
+
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+func (p *ollamaProvider) options() *OllamaOptions {
+	params := p.cfg.GenerationParams
+	if params.IsZero() && p.cfg.ContextWindow == 0 {
+		return nil
+	}
+	return &OllamaOptions{
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+		NumPredict:  params.MaxTokens,
+		NumCtx:      p.cfg.ContextWindow,
+		Seed:        params.Seed,
+		Stop:        params.Stop,
+	}
+}
+
+func (p *ollamaProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	url := p.cfg.URL + "/api/generate"
+	log.Printf("正在调用Ollama API: %s", url)
+
+	req := OllamaRequest{
+		Model:   p.cfg.Model,
+		Prompt:  prompt,
+		Format:  opts.Schema,
+		Options: p.options(),
+	}
+	if opts.OnToken != nil {
+		req.Stream = true
+		return p.stream(ctx, url, req, opts.OnToken)
+	}
+
+	body, err := postJSON(ctx, url, nil, req, ollamaError)
+	if err != nil {
+		return "", fmt.Errorf("请求Ollama失败: %w", err)
+	}
+
+	var resp OllamaResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	return resp.Response, nil
+}
+
+func (p *ollamaProvider) Chat(ctx context.Context, messages []Message, opts GenerateOptions) (string, error) {
+	url := p.cfg.URL + "/api/chat"
+	req := ollamaChatRequest{
+		Model:    p.cfg.Model,
+		Messages: messages,
+		Format:   opts.Schema,
+		Options:  p.options(),
+	}
+	if opts.OnToken != nil {
+		req.Stream = true
+		return p.stream(ctx, url, req, opts.OnToken)
+	}
+
+	body, err := postJSON(ctx, url, nil, req, ollamaError)
+	if err != nil {
+		return "", fmt.Errorf("请求Ollama失败: %w", err)
+	}
+
+	var resp ollamaChatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	return resp.Message.Content, nil
+}
+
+// stream 读取 Ollama 的 NDJSON 流，每行一个 chunk，done=true 为最后一行；出错时返回已收到的部分文本
+func (p *ollamaProvider) stream(ctx context.Context, url string, payload any, onToken func(string)) (string, error) {
+	var text strings.Builder
+	err := postStream(ctx, url, nil, payload, ollamaError, func(line string) (bool, error) {
+		if strings.TrimSpace(line) == "" {
+			return false, nil
+		}
+		var chunk ollamaStreamChunk
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return false, fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		if chunk.Error != "" {
+			return false, fmt.Errorf("Ollama流式响应出错: %s", chunk.Error)
+		}
+		token := chunk.Response + chunk.Message.Content
+		if token != "" {
+			text.WriteString(token)
+			onToken(token)
+		}
+		return chunk.Done, nil
+	})
+	if err != nil {
+		return text.String(), fmt.Errorf("请求Ollama失败: %w", err)
+	}
+	return text.String(), nil
+}
+
+func (p *ollamaProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	body, err := postJSON(ctx, p.cfg.URL+"/api/embed", nil, ollamaEmbedRequest{
+		Model: p.cfg.EmbeddingModel,
+		Input: texts,
+	}, ollamaError)
+	if err != nil {
+		return nil, fmt.Errorf("请求Ollama向量失败: %w", err)
+	}
+
+	var resp ollamaEmbedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("Ollama返回 %d 个向量，期望 %d 个", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
+
+func ollamaError(status int, body []byte) error {
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Error != "" {
+		return fmt.Errorf("Ollama返回错误状态码 %d: %s", status, resp.Error)
+	}
+	return fmt.Errorf("Ollama返回错误状态码 %d: %s", status, string(body))
+}
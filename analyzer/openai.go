@@ -0,0 +1,262 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"biliTagAnalyse/cmd"
+)
+
+// defaultOpenAIEmbeddingModel 是未配置 embedding_model 时使用的向量模型，对话模型不能用于 /embeddings
+const defaultOpenAIEmbeddingModel = "text-embedding-3-small"
+
+func init() {
+	RegisterProvider("openai", newOpenAIProvider)
+}
+
+// OpenAIConfig 适用于所有兼容 OpenAI chat/completions 的端点（OpenAI、DeepSeek、通义千问、vLLM 等）
+type OpenAIConfig struct {
+	Endpoint string `json:"endpoint"`
+	APIKey   string `json:"api_key"`
+	Model    string `json:"model"`
+	// EmbeddingsEndpoint 为向量接口地址，为空时由以 /chat/completions 结尾的 Endpoint 推导
+	EmbeddingsEndpoint string `json:"embeddings_endpoint"`
+	// EmbeddingModel 为向量接口使用的模型，为空时使用 text-embedding-3-small
+	EmbeddingModel string `json:"embedding_model"`
+	cmd.GenerationParams
+}
+
+type openAIProvider struct {
+	cfg OpenAIConfig
+}
+
+func newOpenAIProvider(raw json.RawMessage, opts *cmd.Options) (Provider, error) {
+	var cfg OpenAIConfig
+	if err := decodeProviderConfig(raw, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = opts.APIEndpoint
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = opts.APIKey
+	}
+	if cfg.Model == "" {
+		cfg.Model = opts.APIModel
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaultAPIModel
+	}
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = defaultOpenAIEmbeddingModel
+	}
+	cfg.GenerationParams.Fill(opts.Generation)
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf(cmd.ErrAPIEndpoint)
+	}
+	if cfg.EmbeddingsEndpoint == "" {
+		cfg.EmbeddingsEndpoint = openAIEmbeddingsEndpoint(cfg.Endpoint)
+	}
+	return &openAIProvider{cfg: cfg}, nil
+}
+
+// openAIEmbeddingsEndpoint 把 .../chat/completions 换成 .../embeddings，其他形式的地址无法推导，返回空字符串
+func openAIEmbeddingsEndpoint(endpoint string) string {
+	base, ok := strings.CutSuffix(strings.TrimRight(endpoint, "/"), "/chat/completions")
+	if !ok {
+		return ""
+	}
+	return base + "/embeddings"
+}
+
+func (p *openAIProvider) Name() string  { return "openai" }
+func (p *openAIProvider) Model() string { return p.cfg.Model }
+
+func (p *openAIProvider) EmbeddingModel() string { return p.cfg.EmbeddingModel }
+
+// CacheScope 为端点地址，不同的兼容服务可能以相同的模型名提供不同的模型
+func (p *openAIProvider) CacheScope() string { return p.cfg.Endpoint }
+
+func (p *openAIProvider) Params() cmd.GenerationParams { return p.cfg.GenerationParams }
+
+func (p *openAIProvider) Capabilities() Capabilities {
+	return Capabilities{Chat: true, Stream: true, Embed: true, JSONMode: true}
+}
+
+// APIRequest 内嵌的生成参数字段名与 OpenAI 一致（temperature、top_p、max_tokens、seed、stop）
+type APIRequest struct {
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	cmd.GenerationParams
+}
+
+// ResponseFormat 使用 json_object 而非 json_schema，兼容 DeepSeek、通义千问、vLLM 等 OpenAI 兼容端点
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type APIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// apiStreamChunk 是 SSE 中一条 data: 消息，出错时部分端点会在流中返回 error 对象
+type apiStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type embeddingsRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (p *openAIProvider) headers() map[string]string {
+	if p.cfg.APIKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + p.cfg.APIKey}
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	return p.Chat(ctx, []Message{{Role: "user", Content: prompt}}, opts)
+}
+
+func (p *openAIProvider) Chat(ctx context.Context, messages []Message, opts GenerateOptions) (string, error) {
+	reqBody := APIRequest{
+		Model:            p.cfg.Model,
+		Messages:         messages,
+		GenerationParams: p.cfg.GenerationParams,
+	}
+	if opts.Schema != nil {
+		reqBody.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+
+	log.Printf("正在调用远程API: %s", p.cfg.Endpoint)
+	if opts.OnToken != nil {
+		reqBody.Stream = true
+		return p.stream(ctx, reqBody, opts.OnToken)
+	}
+
+	body, err := postJSON(ctx, p.cfg.Endpoint, p.headers(), reqBody, openAIError)
+	if err != nil {
+		return "", fmt.Errorf("请求远程API失败: %w", err)
+	}
+
+	var apiResp APIResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	if apiResp.Error != nil {
+		return "", fmt.Errorf("API错误: %s", apiResp.Error.Message)
+	}
+	if len(apiResp.Choices) == 0 {
+		return "", fmt.Errorf("API返回空响应")
+	}
+	return apiResp.Choices[0].Message.Content, nil
+}
+
+// stream 读取 SSE 流：只处理 data: 行，data: [DONE] 或 finish_reason 非空表示结束；出错时返回已收到的部分文本
+func (p *openAIProvider) stream(ctx context.Context, reqBody APIRequest, onToken func(string)) (string, error) {
+	var text strings.Builder
+	err := postStream(ctx, p.cfg.Endpoint, p.headers(), reqBody, openAIError, func(line string) (bool, error) {
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			return false, nil
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return true, nil
+		}
+
+		var chunk apiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		if chunk.Error != nil {
+			return false, fmt.Errorf("API流式响应出错: %s", chunk.Error.Message)
+		}
+		done := false
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				onToken(choice.Delta.Content)
+			}
+			if choice.FinishReason != nil && *choice.FinishReason != "" {
+				done = true
+			}
+		}
+		return done, nil
+	})
+	if err != nil {
+		return text.String(), fmt.Errorf("请求远程API失败: %w", err)
+	}
+	return text.String(), nil
+}
+
+func (p *openAIProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if p.cfg.EmbeddingsEndpoint == "" {
+		return nil, fmt.Errorf("%w：无法从 %s 推导向量接口地址，请在 providers.openai 中设置 embeddings_endpoint", errEmbedUnavailable, p.cfg.Endpoint)
+	}
+	body, err := postJSON(ctx, p.cfg.EmbeddingsEndpoint, p.headers(), embeddingsRequest{
+		Model: p.cfg.EmbeddingModel,
+		Input: texts,
+	}, openAIError)
+	if err != nil {
+		return nil, fmt.Errorf("请求向量接口失败: %w", err)
+	}
+
+	var resp embeddingsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	vectors := make([][]float64, len(texts))
+	for _, item := range resp.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = item.Embedding
+		}
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("向量接口缺少第 %d 个输入的结果", i)
+		}
+	}
+	return vectors, nil
+}
+
+func openAIError(status int, body []byte) error {
+	var resp struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Error != nil && resp.Error.Message != "" {
+		return fmt.Errorf("API返回错误状态码 %d: %s", status, resp.Error.Message)
+	}
+	return fmt.Errorf("API返回错误状态码 %d: %s", status, string(body))
+}
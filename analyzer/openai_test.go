@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"biliTagAnalyse/cmd"
+)
+
+func newTestOpenAIProvider(t *testing.T, url string) Provider {
+	t.Helper()
+	provider, err := newOpenAIProvider(nil, &cmd.Options{APIEndpoint: url, APIKey: "sk-test", APIModel: "gpt-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestOpenAIChat(t *testing.T) {
+	var got APIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("请求体不是合法 JSON: %v", err)
+		}
+		io.WriteString(w, `{"choices":[{"message":{"content":"{\"summary\":\"ok\"}"}}]}`)
+	}))
+	defer server.Close()
+
+	text, err := newTestOpenAIProvider(t, server.URL).Complete(context.Background(), "分析", GenerateOptions{Schema: map[string]any{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != `{"summary":"ok"}` {
+		t.Errorf("回答 = %q", text)
+	}
+	if got.Model != "gpt-test" || got.Stream || got.ResponseFormat == nil || got.ResponseFormat.Type != "json_object" {
+		t.Errorf("请求字段不正确: %+v", got)
+	}
+}
+
+func TestOpenAIStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req APIRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("设置 OnToken 时应请求流式输出")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, ": keep-alive\n\n")
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"你好\"}}]}\n\n")
+		io.WriteString(w, "data:{\"choices\":[{\"delta\":{\"content\":\"世界\"}}]}\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var tokens []string
+	text, err := newTestOpenAIProvider(t, server.URL).Complete(context.Background(), "分析", GenerateOptions{
+		OnToken: func(token string) { tokens = append(tokens, token) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "你好世界" || strings.Join(tokens, "|") != "你好|世界" {
+		t.Errorf("text=%q tokens=%v", text, tokens)
+	}
+}
+
+func TestOpenAIStreamErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantText string
+		wantErr  string
+	}{
+		{
+			name:     "流中返回错误",
+			body:     "data: {\"choices\":[{\"delta\":{\"content\":\"部分\"}}]}\n\ndata: {\"error\":{\"message\":\"rate limited\"}}\n\n",
+			wantText: "部分",
+			wantErr:  "rate limited",
+		},
+		{
+			name:     "连接提前关闭",
+			body:     "data: {\"choices\":[{\"delta\":{\"content\":\"部分\"}}]}\n\n",
+			wantText: "部分",
+			wantErr:  errStreamEOF.Error(),
+		},
+		{
+			name:     "finish_reason 结束",
+			body:     "data: {\"choices\":[{\"delta\":{\"content\":\"完整\"},\"finish_reason\":\"stop\"}]}\n\n",
+			wantText: "完整",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			text, err := newTestOpenAIProvider(t, server.URL).Complete(context.Background(), "分析", GenerateOptions{OnToken: func(string) {}})
+			if text != tt.wantText {
+				t.Errorf("text = %q，期望 %q", text, tt.wantText)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("不应出错: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("错误应包含 %q，实际: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOpenAIErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`)
+	}))
+	defer server.Close()
+
+	for _, opts := range []GenerateOptions{{}, {OnToken: func(string) {}}} {
+		_, err := newTestOpenAIProvider(t, server.URL).Complete(context.Background(), "分析", opts)
+		if err == nil || !strings.Contains(err.Error(), "401: Incorrect API key provided") {
+			t.Errorf("流式=%v 时错误应包含状态码和说明，实际: %v", opts.OnToken != nil, err)
+		}
+	}
+}
+
+func TestOpenAIEmbeddingsEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint, want string
+	}{
+		{"https://api.openai.com/v1/chat/completions", "https://api.openai.com/v1/embeddings"},
+		{"https://api.example.com/v1/chat/completions/", "https://api.example.com/v1/embeddings"},
+		{"https://api.example.com/v1/chat", ""},
+		{"https://api.example.com/v1", ""},
+	}
+	for _, tt := range tests {
+		if got := openAIEmbeddingsEndpoint(tt.endpoint); got != tt.want {
+			t.Errorf("openAIEmbeddingsEndpoint(%q) = %q，期望 %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAIEmbed(t *testing.T) {
+	var got embeddingsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("请求路径 = %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("请求体不是合法 JSON: %v", err)
+		}
+		// 结果按 index 归位，与返回顺序无关
+		io.WriteString(w, `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`)
+	}))
+	defer server.Close()
+
+	provider := newTestOpenAIProvider(t, server.URL+"/v1/chat/completions")
+	if provider.EmbeddingModel() != defaultOpenAIEmbeddingModel {
+		t.Errorf("未配置 embedding_model 时应使用 %s，而不是对话模型，实际 %s", defaultOpenAIEmbeddingModel, provider.EmbeddingModel())
+	}
+	vectors, err := provider.Embed(context.Background(), []string{"游戏", "音乐"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != defaultOpenAIEmbeddingModel || len(got.Input) != 2 {
+		t.Errorf("请求字段不正确: %+v", got)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("向量 = %v", vectors)
+	}
+}
+
+func TestOpenAIEmbedWithoutEndpoint(t *testing.T) {
+	_, err := newTestOpenAIProvider(t, "https://api.example.com/v1/chat").Embed(context.Background(), []string{"游戏"})
+	if !errors.Is(err, errEmbedUnavailable) || !strings.Contains(err.Error(), "embeddings_endpoint") {
+		t.Fatalf("无法推导向量接口地址时应报错并提示配置 embeddings_endpoint，实际: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"biliTagAnalyse/crawler"
+	"biliTagAnalyse/statistics"
+)
+
+// DefaultPrompt 是未指定 -prompt 时使用的内置提示词
+const DefaultPrompt = "default"
+
+//go:embed prompts/*.tmpl
+var promptFS embed.FS
+
+// PromptData 是提示词模板的数据：完整统计结果、原始视频（没有原始数据时为空）和 Top 20 Tag
+type PromptData struct {
+	Stats   *statistics.StatsResult
+	Videos  []*crawler.VideoInfo
+	TopTags []statistics.TagStat
+}
+
+// PromptPreset 是一个内置提示词
+type PromptPreset struct {
+	Name        string
+	Description string
+}
+
+// PromptRecord 记录实际发送的提示词，写入 analysis_result.json 便于复现
+type PromptRecord struct {
+	Name     string `json:"name"`
+	Source   string `json:"source"`
+	Rendered string `json:"rendered"`
+}
+
+// promptDescription 匹配模板首行的 {{/* 说明 */}} 注释
+var promptDescription = regexp.MustCompile(`^\{\{/\*\s*(.*?)\s*\*/`)
+
+var promptFuncs = template.FuncMap{
+	"add":  func(a, b int) int { return a + b },
+	"join": strings.Join,
+	// percent 将 0~1 的比例格式化为百分数
+	"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) },
+	// ratio 将 a/b 格式化为百分数，b 为 0 时输出 "-"
+	"ratio": func(a, b int) string {
+		if b == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", float64(a)/float64(b)*100)
+	},
+	// head 返回任意切片的前 n 项，不足 n 项时原样返回
+	"head": func(n int, list any) any {
+		v := reflect.ValueOf(list)
+		if v.Kind() == reflect.Slice && v.Len() > n {
+			return v.Slice(0, n).Interface()
+		}
+		return list
+	},
+}
+
+// PromptPresets 返回内置提示词列表（按名称排序）
+func PromptPresets() ([]PromptPreset, error) {
+	entries, err := promptFS.ReadDir("prompts")
+	if err != nil {
+		return nil, err
+	}
+
+	var presets []PromptPreset
+	for _, entry := range entries {
+		data, err := promptFS.ReadFile(path.Join("prompts", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		preset := PromptPreset{Name: strings.TrimSuffix(entry.Name(), ".tmpl")}
+		if m := promptDescription.FindSubmatch(data); m != nil {
+			preset.Description = string(m[1])
+		}
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+// LoadPromptTemplate 按内置名称或文件路径读取提示词模板，返回模板原文和来源（preset 或文件路径）。
+// 参数是已存在的文件或带 .tmpl 扩展名时按路径读取，否则按内置名称查找。
+func LoadPromptTemplate(nameOrPath string) (string, string, error) {
+	if nameOrPath == "" {
+		nameOrPath = DefaultPrompt
+	}
+
+	if _, err := os.Stat(nameOrPath); err == nil || strings.HasSuffix(nameOrPath, ".tmpl") {
+		data, err := os.ReadFile(nameOrPath)
+		if err != nil {
+			return "", "", fmt.Errorf("读取提示词模板失败: %w", err)
+		}
+		return string(data), nameOrPath, nil
+	}
+
+	data, err := promptFS.ReadFile(path.Join("prompts", nameOrPath+".tmpl"))
+	if err != nil {
+		var names []string
+		presets, _ := PromptPresets()
+		for _, p := range presets {
+			names = append(names, p.Name)
+		}
+		return "", "", fmt.Errorf("未知的提示词: %s（内置: %s，或指定 .tmpl 文件路径）", nameOrPath, strings.Join(names, "|"))
+	}
+	return string(data), "preset", nil
+}
+
+// ParsePromptTemplate 读取并解析提示词模板，返回模板和来源
+func ParsePromptTemplate(nameOrPath string) (*template.Template, string, error) {
+	if nameOrPath == "" {
+		nameOrPath = DefaultPrompt
+	}
+	text, source, err := LoadPromptTemplate(nameOrPath)
+	if err != nil {
+		return nil, "", err
+	}
+	tmpl, err := template.New(path.Base(nameOrPath)).Funcs(promptFuncs).Parse(text)
+	if err != nil {
+		return nil, "", fmt.Errorf("解析提示词模板失败: %w", err)
+	}
+	return tmpl, source, nil
+}
+
+// renderPrompt 渲染 -prompt 指定的模板，Rendered 只包含模板内容，调用方用 withInstruction 附加输出格式后再发送
+func (a *Analyzer) renderPrompt(stats *statistics.StatsResult) (*PromptRecord, error) {
+	name := a.opts.Prompt
+	if name == "" {
+		name = DefaultPrompt
+	}
+
+	tmpl, source, err := ParsePromptTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data := PromptData{Stats: stats, Videos: a.videos, TopTags: stats.TagStats}
+	if len(data.TopTags) > 20 {
+		data.TopTags = data.TopTags[:20]
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("渲染提示词模板失败: %w", err)
+	}
+
+	return &PromptRecord{
+		Name:     name,
+		Source:   source,
+		Rendered: strings.TrimSpace(buf.String()),
+	}, nil
+}
+
+// withInstruction 在提示词末尾附加结构化输出要求，模板中无需自行说明输出格式
+func withInstruction(prompt string) string {
+	return prompt + "\n" + fmt.Sprintf(structuredInstruction, insightTagCount)
+}
@@ -0,0 +1,242 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"biliTagAnalyse/cmd"
+)
+
+const requestTimeout = 120 * time.Second
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Capabilities 描述模型后端支持的能力，调用方据此选择降级路径
+type Capabilities struct {
+	Chat       bool // 支持多轮消息
+	Stream     bool // 支持流式输出（GenerateOptions.OnToken）
+	Embed      bool // 支持文本向量
+	JSONSchema bool // 能按 JSON Schema 约束输出
+	JSONMode   bool // 只保证输出合法 JSON，结构由提示词约束
+}
+
+// GenerateOptions 是单次生成请求的参数
+type GenerateOptions struct {
+	// Schema 非 nil 时要求模型输出符合该 JSON Schema 的 JSON
+	Schema any
+	// OnToken 非 nil 且后端支持流式输出时，每收到一段文本就回调一次；返回值仍是完整文本
+	OnToken func(token string)
+}
+
+// Provider 是一个大模型后端。新增后端只需实现该接口并在 init 中 RegisterProvider。
+type Provider interface {
+	Name() string
+	Model() string
+	Capabilities() Capabilities
+	// Params 返回该后端每次请求发送的生成参数，用于记录到分析结果
+	Params() cmd.GenerationParams
+	Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error)
+	Chat(ctx context.Context, messages []Message, opts GenerateOptions) (string, error)
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+	// EmbeddingModel 返回 Embed 使用的模型名称，不支持向量的后端返回空字符串
+	EmbeddingModel() string
+	// CacheScope 返回模型名和生成参数之外影响回答的设置（端点地址、上下文长度等），计入回答缓存的键
+	CacheScope() string
+}
+
+// ProviderFactory 根据 config.json 中 providers.<name> 配置块创建后端。
+// 配置块中留空的字段由工厂从命令行参数、环境变量和顶层旧字段（cmd.Options）中补齐。
+type ProviderFactory func(raw json.RawMessage, opts *cmd.Options) (Provider, error)
+
+var providers = make(map[string]ProviderFactory)
+
+func RegisterProvider(name string, factory ProviderFactory) {
+	if _, exists := providers[name]; exists {
+		panic(fmt.Sprintf("analyzer: 重复注册模型后端 %s", name))
+	}
+	providers[name] = factory
+}
+
+// ProviderNames 返回已注册的后端名称（按字母排序）
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateAPIFormat 检查 api_format 是否为已注册的模型后端，空值表示使用默认协议。
+// 命令行和 config.json 合并后再校验，新注册的后端无需修改可选列表。
+func ValidateAPIFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	if _, ok := providers[format]; !ok {
+		return fmt.Errorf("不支持的API协议: %s（可选: %v）", format, ProviderNames())
+	}
+	return nil
+}
+
+func NewProvider(name string, raw json.RawMessage, opts *cmd.Options) (Provider, error) {
+	factory, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的模型后端: %s（可选: %v）", name, ProviderNames())
+	}
+	return factory(raw, opts)
+}
+
+// decodeProviderConfig 解析配置块，块不存在时保留默认值
+func decodeProviderConfig(raw json.RawMessage, cfg any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, cfg); err != nil {
+		return fmt.Errorf("解析模型后端配置失败: %w", err)
+	}
+	return nil
+}
+
+// explicitEndpoint 返回 -api-endpoint（api_endpoint）的值，仅当 -api-format 明确选择了 format 时才使用：
+// 该地址默认对应 OpenAI 兼容接口，通过 -provider 或 config.json 选择其他后端时不能沿用。
+func explicitEndpoint(opts *cmd.Options, format string) string {
+	if opts.APIFormat != format {
+		return ""
+	}
+	return opts.APIEndpoint
+}
+
+// splitSystem 将 system 消息拼接成一段，供 system 指令与对话分开传递的接口使用
+func splitSystem(messages []Message) (string, []Message) {
+	var system []string
+	rest := make([]Message, 0, len(messages))
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = append(system, m.Content)
+			continue
+		}
+		rest = append(rest, m)
+	}
+	return strings.Join(system, "\n\n"), rest
+}
+
+// newJSONRequest 构造带 JSON 请求体和自定义请求头的 POST 请求
+func newJSONRequest(ctx context.Context, url string, headers map[string]string, payload any) (*http.Request, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return req, nil
+}
+
+// postJSON 发送 JSON 请求并返回响应体，非 2xx 状态码交给 decodeError 转成后端特有的错误信息
+func postJSON(ctx context.Context, url string, headers map[string]string, payload any, decodeError func(status int, body []byte) error) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := newJSONRequest(ctx, url, headers, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, decodeError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+var (
+	errStreamIdle = errors.New("流式响应超时")
+	errStreamEOF  = errors.New("流式响应意外结束")
+	// errEmbedUnavailable 表示后端配置不足以请求向量，重试其他批次也不会成功
+	errEmbedUnavailable = errors.New("向量接口不可用")
+)
+
+// postStream 发送 JSON 请求并逐行回调响应体（NDJSON 或 SSE），onLine 返回 done=true 时结束读取。
+// 流式响应没有整体超时，连续 requestTimeout 未收到数据才视为超时；ctx 被取消时立即中断连接。
+// 服务端在 done 之前关闭连接时返回 errStreamEOF。
+func postStream(ctx context.Context, url string, headers map[string]string, payload any, decodeError func(status int, body []byte) error, onLine func(line string) (done bool, err error)) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	idle := time.AfterFunc(requestTimeout, func() { cancel(errStreamIdle) })
+	defer idle.Stop()
+
+	req, err := newJSONRequest(ctx, url, headers, payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return streamError(ctx, fmt.Errorf("请求失败: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return decodeError(resp.StatusCode, body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		idle.Reset(requestTimeout)
+		done, err := onLine(scanner.Text())
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return streamError(ctx, fmt.Errorf("读取响应失败: %w", err))
+	}
+	return streamError(ctx, errStreamEOF)
+}
+
+// streamError 区分用户取消、空闲超时和普通网络错误
+func streamError(ctx context.Context, err error) error {
+	switch cause := context.Cause(ctx); {
+	case errors.Is(cause, errStreamIdle):
+		return fmt.Errorf("%w（%v 内未收到数据）", errStreamIdle, requestTimeout)
+	case cause != nil:
+		return fmt.Errorf("已取消: %w", cause)
+	default:
+		return err
+	}
+}
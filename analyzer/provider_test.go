@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"biliTagAnalyse/cmd"
+)
+
+func TestValidateAPIFormatUsesRegistry(t *testing.T) {
+	for _, format := range append(ProviderNames(), "") {
+		if err := ValidateAPIFormat(format); err != nil {
+			t.Errorf("ValidateAPIFormat(%q) = %v", format, err)
+		}
+	}
+
+	err := ValidateAPIFormat("claude")
+	if err == nil {
+		t.Fatal("未注册的协议应报错")
+	}
+	for _, name := range ProviderNames() {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("错误信息 %q 应列出已注册的后端 %s", err, name)
+		}
+	}
+}
+
+func TestValidateAPIFormatAcceptsNewlyRegistered(t *testing.T) {
+	RegisterProvider("test-format", func(json.RawMessage, *cmd.Options) (Provider, error) { return nil, nil })
+	defer delete(providers, "test-format")
+
+	if err := ValidateAPIFormat("test-format"); err != nil {
+		t.Errorf("新注册的后端应可作为 api_format: %v", err)
+	}
+}
+
+func TestNewAnalyzerRejectsUnknownAPIFormat(t *testing.T) {
+	// JSON 输出模式不创建后端，但合并配置后的 api_format 仍会被校验
+	opts := &cmd.Options{RunMode: cmd.ModeJSONOnly, APIFormat: "claude"}
+	if _, err := NewAnalyzer(opts, nil); err == nil || !strings.Contains(err.Error(), "claude") {
+		t.Errorf("NewAnalyzer 应拒绝未注册的 api_format，得到 %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"biliTagAnalyse/statistics"
+)
+
+// StructuredAnalysis 是要求模型返回的 JSON 结构，解析后映射到 AnalysisResult 的各字段。
+type StructuredAnalysis struct {
+	Summary     string              `json:"summary"`
+	Trends      []string            `json:"trends"`
+	Suggestions []string            `json:"suggestions"`
+	TagInsights []StructuredInsight `json:"tag_insights"`
+}
+
+type StructuredInsight struct {
+	Tag         string `json:"tag"`
+	Description string `json:"description"`
+}
+
+// analysisSchema 同时用作 Ollama 的 format 参数和提示词中的格式说明
+var analysisSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"summary": map[string]any{"type": "string"},
+		"trends": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+		"suggestions": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+		"tag_insights": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tag":         map[string]any{"type": "string"},
+					"description": map[string]any{"type": "string"},
+				},
+				"required": []string{"tag", "description"},
+			},
+		},
+	},
+	"required": []string{"summary", "trends", "suggestions", "tag_insights"},
+}
+
+const structuredInstruction = `
+请严格只输出一个 JSON 对象，不要输出 Markdown 代码块或其他文字，格式如下：
+{
+  "summary": "整体分析摘要（一段话）",
+  "trends": ["内容趋势1", "内容趋势2"],
+  "suggestions": ["创作建议1", "创作建议2"],
+  "tag_insights": [{"tag": "Tag名称", "description": "该Tag反映的内容和用户偏好（一句话）"}]
+}
+tag_insights 需覆盖排名前 %d 的每个 Tag，tag 字段必须与上面列表中的名称完全一致。`
+
+var (
+	codeFence     = regexp.MustCompile("(?s)^```[a-zA-Z]*\\s*(.*?)\\s*```$")
+	trailingComma = regexp.MustCompile(`,\s*([}\]])`)
+)
+
+// ParseStructured 解析模型输出的分析结果 JSON，全部修复尝试失败时返回错误，由调用方降级处理。
+func ParseStructured(text string) (*StructuredAnalysis, error) {
+	var result StructuredAnalysis
+	if err := decodeRepaired(text, &result); err != nil {
+		return nil, err
+	}
+	if err := result.validate(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// decodeRepaired 将模型输出的 JSON 解析到 v，依次尝试：原文、去掉代码块、截取最外层对象、
+// 删除多余逗号、补齐被截断的括号。
+func decodeRepaired(text string, v any) error {
+	candidate := strings.TrimSpace(text)
+	if match := codeFence.FindStringSubmatch(candidate); match != nil {
+		candidate = match[1]
+	}
+	if start := strings.Index(candidate, "{"); start >= 0 {
+		candidate = candidate[start:]
+	}
+	object := candidate
+	if end := strings.LastIndex(candidate, "}"); end >= 0 {
+		object = candidate[:end+1]
+	}
+
+	attempts := []string{
+		object,
+		trailingComma.ReplaceAllString(object, "$1"),
+		closeBrackets(candidate),
+	}
+
+	for _, attempt := range attempts {
+		if json.Valid([]byte(attempt)) {
+			if err := json.Unmarshal([]byte(attempt), v); err != nil {
+				return fmt.Errorf("模型输出的JSON结构不符合要求: %w", err)
+			}
+			return nil
+		}
+	}
+	err := json.Unmarshal([]byte(object), v)
+	return fmt.Errorf("模型输出不是有效的JSON: %w", err)
+}
+
+func (s *StructuredAnalysis) validate() error {
+	s.Summary = strings.TrimSpace(s.Summary)
+	s.Trends = compactStrings(s.Trends)
+	s.Suggestions = compactStrings(s.Suggestions)
+
+	insights := s.TagInsights[:0]
+	for _, insight := range s.TagInsights {
+		insight.Tag = strings.TrimSpace(insight.Tag)
+		insight.Description = strings.TrimSpace(insight.Description)
+		if insight.Tag != "" && insight.Description != "" {
+			insights = append(insights, insight)
+		}
+	}
+	s.TagInsights = insights
+
+	if s.Summary == "" && len(s.Trends) == 0 && len(s.Suggestions) == 0 && len(s.TagInsights) == 0 {
+		return fmt.Errorf("模型返回的JSON缺少 summary/trends/suggestions/tag_insights")
+	}
+	return nil
+}
+
+func compactStrings(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// closeBrackets 为被截断的输出补齐未闭合的字符串和括号
+func closeBrackets(s string) string {
+	var stack []byte
+	inString, escaped := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			stack = append(stack, '}')
+		case c == '[':
+			stack = append(stack, ']')
+		case (c == '}' || c == ']') && len(stack) > 0:
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(s, ", \n\t"))
+	if inString {
+		b.WriteByte('"')
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		b.WriteByte(stack[i])
+	}
+	return trailingComma.ReplaceAllString(b.String(), "$1")
+}
+
+const repairInstruction = `你上一次的回答无法解析为 JSON（%v）。请把下面的回答整理成符合要求的 JSON 对象，不要增删内容：
+
+%s
+%s`
+
+// structuredResponse 返回可以解析的模型输出：本地修复失败时把原回答和错误交给模型重新输出一次，
+// 仍然失败（或重试请求出错）时返回原回答，由 applyStructured 降级为原始文本摘要。
+func (a *Analyzer) structuredResponse(ctx context.Context, response string) string {
+	_, err := ParseStructured(response)
+	if err == nil || ctx.Err() != nil {
+		return response
+	}
+	log.Printf("模型输出无法解析，要求模型重新输出 JSON: %v", err)
+	prompt := fmt.Sprintf(repairInstruction, err, response, fmt.Sprintf(structuredInstruction, insightTagCount))
+	retry, retryErr := a.generate(ctx, prompt, false)
+	if retryErr != nil {
+		log.Printf("重新输出 JSON 失败: %v", retryErr)
+		return response
+	}
+	if _, err := ParseStructured(retry); err != nil {
+		log.Printf("重新输出的内容仍无法解析: %v", err)
+		return response
+	}
+	return retry
+}
+
+// applyStructured 将模型输出映射到分析结果；无法解析时整段文本作为摘要，并在 Warnings 中说明。
+func applyStructured(result *AnalysisResult, stats *statistics.StatsResult, response string) {
+	structured, err := ParseStructured(response)
+	if err != nil {
+		result.Summary = strings.TrimSpace(response)
+		result.Warnings = append(result.Warnings, fmt.Sprintf("结构化输出解析失败，已使用原始文本作为摘要: %v", err))
+		result.TopTags = topTagInsights(stats, nil)
+		return
+	}
+
+	result.Summary = structured.Summary
+	result.Trends = structured.Trends
+	result.Suggestions = structured.Suggestions
+
+	descriptions := make(map[string]string, len(structured.TagInsights))
+	for _, insight := range structured.TagInsights {
+		descriptions[insight.Tag] = insight.Description
+	}
+	result.TopTags = topTagInsights(stats, descriptions)
+}
+
+func topTagInsights(stats *statistics.StatsResult, descriptions map[string]string) []TagInsight {
+	insights := make([]TagInsight, 0, insightTagCount)
+	for i := 0; i < len(stats.TagStats) && i < insightTagCount; i++ {
+		insights = append(insights, TagInsight{
+			Tag:         stats.TagStats[i].Tag,
+			Count:       stats.TagStats[i].Count,
+			Description: descriptions[stats.TagStats[i].Tag],
+		})
+	}
+	return insights
+}
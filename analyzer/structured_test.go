@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"biliTagAnalyse/cmd"
+	"biliTagAnalyse/statistics"
+)
+
+func TestParseStructuredRepairs(t *testing.T) {
+	tests := []struct {
+		name, text, summary string
+		trends              int
+	}{
+		{"原文", `{"summary":"摘要","trends":["a"]}`, "摘要", 1},
+		{"代码块", "```json\n{\"summary\":\"摘要\",\"trends\":[\"a\",\"b\"]}\n```", "摘要", 2},
+		{"前后多余文字", "好的，分析如下：\n{\"summary\":\"摘要\"}\n希望有帮助", "摘要", 0},
+		{"多余逗号", `{"summary":"摘要","trends":["a","b",],}`, "摘要", 2},
+		{"截断的对象", `{"summary":"摘要","trends":["a"]`, "摘要", 1},
+		{"截断的数组", `{"summary":"摘要","trends":["a","b"`, "摘要", 2},
+		{"截断在字符串中", `{"summary":"摘要","trends":["a","未完`, "摘要", 2},
+		{"截断在逗号后", "{\"summary\":\"摘要\",\"trends\":[\"a\",\n", "摘要", 1},
+		{"字符串中的括号", `{"summary":"含有 } 和 ] 的{摘要}","trends":["[a]"`, "含有 } 和 ] 的{摘要}", 1},
+		{"字符串中的转义引号", `{"summary":"他说\"好\"}","trends":["a"`, `他说"好"}`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStructured(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Summary != tt.summary || len(got.Trends) != tt.trends {
+				t.Errorf("解析结果 = %+v", got)
+			}
+		})
+	}
+}
+
+func TestParseStructuredRejects(t *testing.T) {
+	for name, text := range map[string]string{
+		"纯文本":   "这些 Tag 说明用户喜欢游戏",
+		"字段全空":  `{"summary":" ","trends":[],"tag_insights":[{"tag":"a","description":""}]}`,
+		"结构不符合": `{"summary":["不是字符串"]}`,
+	} {
+		if _, err := ParseStructured(text); err == nil {
+			t.Errorf("%s: 应报错", name)
+		}
+	}
+}
+
+func TestCloseBrackets(t *testing.T) {
+	tests := map[string]string{
+		`{"a":[1,2`:          `{"a":[1,2]}`,
+		`{"a":{"b":"x`:       `{"a":{"b":"x"}}`,
+		`{"a":"}]","b":[`:    `{"a":"}]","b":[]}`,
+		`{"a":[1,2],` + "\n": `{"a":[1,2]}`,
+		`{"a":1}`:            `{"a":1}`,
+	}
+	for in, want := range tests {
+		if got := closeBrackets(in); got != want {
+			t.Errorf("closeBrackets(%q) = %q，期望 %q", in, got, want)
+		}
+	}
+}
+
+// scriptedProvider 按顺序返回预设的回答，并记录收到的提示词
+type scriptedProvider struct {
+	mockProvider
+	responses []string
+	prompts   []string
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if len(p.responses) == 0 {
+		return "", context.DeadlineExceeded
+	}
+	response := p.responses[0]
+	p.responses = p.responses[1:]
+	return response, nil
+}
+
+func TestStructuredResponseReprompt(t *testing.T) {
+	stats := &statistics.StatsResult{TagStats: []statistics.TagStat{{Tag: "游戏", Count: 3}}}
+
+	tests := []struct {
+		name      string
+		responses []string
+		calls     int
+		summary   string
+		warned    bool
+	}{
+		{"无需重试", []string{`{"summary":"直接解析"}`}, 1, "直接解析", false},
+		{"重试成功", []string{"游戏内容很多", `{"summary":"重新输出"}`}, 2, "重新输出", false},
+		{"重试仍失败", []string{"游戏内容很多", "还是文字"}, 2, "游戏内容很多", true},
+		{"重试请求出错", []string{"游戏内容很多"}, 2, "游戏内容很多", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &scriptedProvider{responses: tt.responses}
+			a := &Analyzer{opts: &cmd.Options{}, provider: provider}
+
+			response, err := a.generate(context.Background(), "分析", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := a.newResult(stats)
+			applyStructured(result, stats, a.structuredResponse(context.Background(), response))
+
+			if len(provider.prompts) != tt.calls {
+				t.Fatalf("请求次数 = %d，期望 %d", len(provider.prompts), tt.calls)
+			}
+			if tt.calls > 1 && !strings.Contains(provider.prompts[1], "游戏内容很多") {
+				t.Errorf("重试提示词应包含原回答: %q", provider.prompts[1])
+			}
+			if result.Summary != tt.summary || (len(result.Warnings) > 0) != tt.warned {
+				t.Errorf("摘要 = %q，警告 = %v", result.Summary, result.Warnings)
+			}
+		})
+	}
+}
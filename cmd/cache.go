@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+)
+
+const (
+	CacheStats = "stats"
+	CacheClear = "clear"
+)
+
+type CacheOptions struct {
+	Action  string
+	Dir     string
+	Expired bool
+}
+
+// ParseCache 解析 cache 子命令：stats 查看缓存概况，clear 清空缓存（-expired 只清理过期记录）
+func ParseCache(args []string) (*CacheOptions, error) {
+	opts := &CacheOptions{Action: CacheStats}
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		opts.Action = args[0]
+		args = args[1:]
+	}
+	if opts.Action != CacheStats && opts.Action != CacheClear {
+		return nil, fmt.Errorf(ErrCacheAction, opts.Action)
+	}
+
+	fs := flag.NewFlagSet(CommandCache, flag.ContinueOnError)
+	dir := fs.String("dir", "", "缓存目录（默认使用配置 response_cache.dir）")
+	expired := fs.Bool("expired", false, "clear 时只删除过期的记录")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	opts.Dir = *dir
+	opts.Expired = *expired
+	return opts, nil
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type RunMode int
@@ -12,6 +14,7 @@ const (
 	ModeJSONOnly RunMode = iota
 	ModeOllama
 	ModeAPI
+	ModeProvider
 )
 
 func (m RunMode) String() string {
@@ -22,6 +25,8 @@ func (m RunMode) String() string {
 		return "ollama"
 	case ModeAPI:
 		return "api"
+	case ModeProvider:
+		return "provider"
 	default:
 		return "unknown"
 	}
@@ -34,8 +39,30 @@ type Options struct {
 	OllamaModel string
 	APIEndpoint string
 	APIKey      string
+	APIModel    string
+	APIFormat   string
+	Generation  GenerationParams
 	ShowHelp    bool
 	InputFile   string
+	Command     string
+	CommandArgs []string
+	Anonymous   bool
+	Baseline    bool
+	Format      string
+	TUI         bool
+	Stream      bool
+	MapReduce   bool
+	WithTitles  bool
+	Categorize  bool
+	Cluster     bool
+	NoCache     bool
+	Provider    string
+	Prompt      string
+
+	// ContextWindow 为 0 表示未指定，分块分析使用默认窗口，Ollama 不发送 num_ctx
+	ContextWindow int
+
+	generationErr error
 }
 
 var (
@@ -47,7 +74,22 @@ var (
 	flagOllamaModel = flag.String("ollama-model", "", "Ollama模型名称")
 	flagAPIEndpoint = flag.String("api-endpoint", "", "远程API端点地址")
 	flagAPIKey      = flag.String("api-key", "", "远程API密钥")
-	flagInput       = flag.String("input", "", "输入JSON文件路径（用于分析模式）")
+	flagAPIModel    = flag.String("api-model", "", "远程API模型名称（默认 gpt-3.5-turbo）")
+	flagAPIFormat   = flag.String("api-format", "", "远程API协议，即模型后端名称（默认 openai）")
+	flagProvider    = flag.String("provider", "", "模型后端名称，指定后使用该后端分析（名称无效时会列出已注册的后端）")
+	flagPrompt      = flag.String("prompt", "", "提示词：内置名称（default|creator-advice|market-research|bubble-audit）或 .tmpl 模板文件路径")
+	flagInput       = flag.String("input", "", "输入JSON或Parquet文件路径（用于分析模式）")
+	flagAnonymous   = flag.Bool("anonymous", false, "匿名模式：不使用Cookie，以未登录状态爬取")
+	flagBaseline    = flag.Bool("baseline", false, "同时进行登录与未登录爬取，计算个性化程度")
+	flagFormat      = flag.String("format", "", "额外导出格式：csv|tsv|xlsx|jsonl|parquet")
+	flagStream      = flag.Bool("stream", false, "流式输出：模型回答边生成边打印到终端")
+	flagMapReduce   = flag.Bool("map-reduce", false, "分块分析：完整 Tag 列表按上下文窗口分块总结后再合并")
+	flagWithTitles  = flag.Bool("with-titles", false, "分块分析时同时提供视频标题")
+	flagCategorize  = flag.Bool("categorize", false, "Tag分类：让模型把 Tag 归入分类表并按分类汇总（结果按 Tag 缓存）")
+	flagCluster     = flag.Bool("cluster", false, "语义聚类：用文本向量把含义相同的 Tag 合并计数（向量按模型和 Tag 缓存）")
+	flagNoCache     = flag.Bool("no-cache", false, "不读取也不写入模型回答缓存，强制重新请求模型")
+	flagContextWin  = flag.Int("context-window", 0, "模型上下文窗口（token），用于计算分块大小，Ollama 同时作为 num_ctx")
+	flagTUI         = flag.Bool("tui", false, "终端面板：实时显示爬取进度和 Top Tag（非终端时退回普通日志）")
 	flagHelp        = flag.Bool("help", false, "显示帮助信息")
 )
 
@@ -87,14 +129,70 @@ func Parse() *Options {
 		apiKey = os.Getenv(EnvAPIKey)
 	}
 
+	apiModel := *flagAPIModel
+	if apiModel == "" {
+		apiModel = os.Getenv(EnvAPIModel)
+	}
+
+	apiFormat := *flagAPIFormat
+	if apiFormat == "" {
+		apiFormat = os.Getenv(EnvAPIFormat)
+	}
+
+	prompt := *flagPrompt
+	if prompt == "" {
+		prompt = os.Getenv(EnvPrompt)
+	}
+
+	contextWindow := *flagContextWin
+	if contextWindow == 0 {
+		if value := os.Getenv(EnvContextWindow); value != "" {
+			contextWindow, _ = strconv.Atoi(value)
+		}
+	}
+
+	// 生成参数：命令行参数优先，未指定的再从环境变量补齐
+	generation := flagGeneration
+	envGeneration, generationErr := generationFromEnv()
+	generation.Fill(envGeneration)
+
+	provider := *flagProvider
+	if provider == "" {
+		provider = os.Getenv(EnvProvider)
+	}
+
 	opts := &Options{
 		ConfigPath:  configPath,
 		OllamaURL:   ollamaURL,
 		OllamaModel: ollamaModel,
 		APIEndpoint: apiEndpoint,
 		APIKey:      apiKey,
+		APIModel:    apiModel,
+		APIFormat:   strings.ToLower(apiFormat),
+		Generation:  generation,
 		ShowHelp:    *flagHelp,
 		InputFile:   *flagInput,
+		Anonymous:   *flagAnonymous,
+		Baseline:    *flagBaseline,
+		Format:      strings.ToLower(*flagFormat),
+		TUI:         *flagTUI,
+		Stream:      *flagStream,
+		MapReduce:   *flagMapReduce,
+		WithTitles:  *flagWithTitles,
+		Categorize:  *flagCategorize,
+		Cluster:     *flagCluster,
+		NoCache:     *flagNoCache,
+
+		ContextWindow: contextWindow,
+		Provider:      strings.ToLower(provider),
+		Prompt:        prompt,
+
+		generationErr: generationErr,
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		opts.Command = args[0]
+		opts.CommandArgs = args[1:]
 	}
 
 	modeCount := 0
@@ -111,6 +209,10 @@ func Parse() *Options {
 		modeCount++
 	}
 
+	if opts.Provider != "" && modeCount == 0 {
+		opts.RunMode = ModeProvider
+	}
+
 	if modeCount > 1 {
 		fmt.Println(ErrMultipleModes)
 		fmt.Println(ErrModePriority)
@@ -130,6 +232,8 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println(HelpUsage)
 	fmt.Println()
+	fmt.Println(HelpCommandSection)
+	fmt.Println()
 	fmt.Println(HelpModeSection)
 	fmt.Println()
 	fmt.Printf(HelpCommonSection+"\n", DefaultConfigPath)
@@ -138,11 +242,39 @@ func printHelp() {
 	fmt.Println()
 	fmt.Println(HelpAPISection)
 	fmt.Println()
+	fmt.Println(HelpGenerationSection)
+	fmt.Println()
 	fmt.Println(HelpExamples)
 	fmt.Println()
 }
 
 func (o *Options) Validate() error {
+	switch o.Command {
+	case "", CommandCompare, CommandMerge, CommandReport, CommandPrompts, CommandCache:
+	default:
+		return fmt.Errorf(ErrUnknownCommand, o.Command)
+	}
+
+	switch o.Format {
+	case "", FormatJSON, FormatCSV, FormatTSV, FormatXLSX, FormatJSONL, FormatParquet:
+	default:
+		return fmt.Errorf(ErrUnknownFormat, o.Format)
+	}
+
+	if o.generationErr != nil {
+		return o.generationErr
+	}
+	if err := o.Generation.Validate(); err != nil {
+		return err
+	}
+	if o.ContextWindow < 0 {
+		return fmt.Errorf(ErrContextWindow, o.ContextWindow)
+	}
+
+	if o.Provider != "" && o.RunMode != ModeJSONOnly {
+		return nil
+	}
+
 	switch o.RunMode {
 	case ModeOllama:
 		if o.OllamaURL == "" {
@@ -152,7 +284,8 @@ func (o *Options) Validate() error {
 			return fmt.Errorf(ErrOllamaModel)
 		}
 	case ModeAPI:
-		if o.APIEndpoint == "" {
+		// Anthropic 和 Gemini 有官方默认地址，只有 OpenAI 兼容端点必须指定
+		if o.APIEndpoint == "" && (o.APIFormat == "" || o.APIFormat == DefaultAPIFormat) {
 			return fmt.Errorf(ErrAPIEndpoint)
 		}
 	}
@@ -160,13 +293,21 @@ func (o *Options) Validate() error {
 }
 
 func (o *Options) ModeDescription() string {
+	if o.Provider != "" && o.RunMode != ModeJSONOnly {
+		return fmt.Sprintf(ModeDescProvider, o.Provider)
+	}
+
 	switch o.RunMode {
 	case ModeJSONOnly:
 		return ModeDescJSON
 	case ModeOllama:
 		return fmt.Sprintf(ModeDescOllama, o.OllamaModel, o.OllamaURL)
 	case ModeAPI:
-		return fmt.Sprintf(ModeDescAPI, o.APIEndpoint)
+		format := o.APIFormat
+		if format == "" {
+			format = DefaultAPIFormat
+		}
+		return fmt.Sprintf(ModeDescAPI, format, o.APIEndpoint)
 	default:
 		return ModeDescUnknown
 	}
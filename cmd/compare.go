@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+type CompareInput struct {
+	Label string
+	Path  string
+}
+
+type CompareOptions struct {
+	Inputs []CompareInput
+	TopK   int
+	Output string
+}
+
+func ParseCompare(args []string) (*CompareOptions, error) {
+	fs := flag.NewFlagSet(CommandCompare, flag.ContinueOnError)
+	topK := fs.Int("top", DefaultCompareTopK, "参与排名相关性计算的 Top K Tag 数")
+	output := fs.String("output", DefaultCompareOutput, "对比结果输出路径")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	opts := &CompareOptions{TopK: *topK, Output: *output}
+	labels := make(map[string]bool)
+	for _, arg := range fs.Args() {
+		input := CompareInput{Path: arg}
+		if label, path, ok := strings.Cut(arg, "="); ok {
+			input.Label, input.Path = label, path
+		}
+		if input.Label != "" {
+			if labels[input.Label] {
+				return nil, fmt.Errorf(ErrCompareLabel, input.Label)
+			}
+			labels[input.Label] = true
+		}
+		opts.Inputs = append(opts.Inputs, input)
+	}
+
+	if len(opts.Inputs) < 2 {
+		return nil, fmt.Errorf(ErrCompareInputs)
+	}
+
+	return opts, nil
+}
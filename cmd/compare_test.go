@@ -0,0 +1,17 @@
+package cmd
+
+import "testing"
+
+func TestParseCompareRejectsDuplicateLabels(t *testing.T) {
+	if _, err := ParseCompare([]string{"新号=a.json", "新号=b.json"}); err == nil {
+		t.Fatal("重复的标签应报错")
+	}
+
+	opts, err := ParseCompare([]string{"-top", "10", "新号=a.json", "游戏号=b.jsonl", "c.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.TopK != 10 || len(opts.Inputs) != 3 || opts.Inputs[1].Label != "游戏号" || opts.Inputs[2].Label != "" {
+		t.Fatalf("解析结果不正确: %+v", opts)
+	}
+}
@@ -4,6 +4,33 @@ const (
 	DefaultConfigPath  = "config.json"
 	DefaultOllamaURL   = "http://localhost:11434"
 	DefaultOllamaModel = "qwen2.5:7b"
+
+	DefaultCompareTopK   = 50
+	DefaultCompareOutput = "results/compare_result.json"
+	DefaultMergeOutput   = "results/tags_stats.json"
+	DefaultReportInput   = "results/analysis_result.json"
+	DefaultReportOutput  = "results/report.html"
+	DefaultReportTopN    = 20
+)
+
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatTSV     = "tsv"
+	FormatXLSX    = "xlsx"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+// DefaultAPIFormat 是 -api 模式未指定 api_format 时使用的协议；可选协议以 analyzer 中注册的模型后端为准
+const DefaultAPIFormat = "openai"
+
+const (
+	CommandCompare = "compare"
+	CommandMerge   = "merge"
+	CommandReport  = "report"
+	CommandPrompts = "prompts"
+	CommandCache   = "cache"
 )
 
 const (
@@ -11,22 +38,53 @@ const (
 	EnvOllamaModel = "BILI_OLLAMA_MODEL"
 	EnvAPIEndpoint = "BILI_API_ENDPOINT"
 	EnvAPIKey      = "BILI_API_KEY"
-	EnvConfigPath  = "BILI_CONFIG_PATH"
+	EnvAPIModel    = "BILI_API_MODEL"
+	EnvAPIFormat   = "BILI_API_FORMAT"
+	EnvProvider    = "BILI_PROVIDER"
+	EnvPrompt      = "BILI_PROMPT"
+	EnvTemperature = "BILI_TEMPERATURE"
+	EnvTopP        = "BILI_TOP_P"
+	EnvMaxTokens   = "BILI_MAX_TOKENS"
+	EnvSeed        = "BILI_SEED"
+	EnvStop        = "BILI_STOP"
+
+	EnvContextWindow = "BILI_CONTEXT_WINDOW"
+	EnvConfigPath    = "BILI_CONFIG_PATH"
 )
 
 const (
 	HelpHeader = "=== B站推荐视频 Tag 分析爬虫 ==="
-	
-	HelpUsage = `用法: biliTagAnalyse [选项]`
+
+	HelpUsage = `用法: biliTagAnalyse [选项] [命令] [命令参数]`
+
+	HelpCommandSection = `命令：
+  compare [-top N] [-output path] 标签=文件 标签=文件 ...
+                  对比多个账号的推荐流（支持统计结果JSON或原始数据JSONL）
+  merge [-output path] 快照文件 快照文件 ...
+                  合并多个进程保存的统计快照（snapshot_file）
+  report [-top N] [-output path] [-markdown path] [-template file] [分析结果文件]
+                  生成单文件HTML报告，可同时生成Markdown报告（默认读取 results/analysis_result.json）
+  prompts [list | show 名称]
+                  列出内置提示词，或输出某个提示词模板原文（可复制后修改，再用 -prompt 文件路径 使用）
+  cache [stats | clear [-expired]] [-dir path]
+                  查看或清空模型回答缓存（默认使用配置 response_cache.dir）`
 
 	HelpModeSection = `运行模式（互斥，优先级从高到低）：
   -json           JSON文件输出模式：仅生成JSON格式文件，不进行模型分析或API调用
   -ollama         Ollama模式：调用本地部署的Ollama模型进行数据分析
-  -api            API模式：调用远程模型API接口完成分析任务`
+  -api            API模式：调用远程模型API接口完成分析任务
+  -provider name  使用指定的模型后端分析（名称无效时会列出已注册的后端，配置见 providers）`
 
 	HelpCommonSection = `通用选项：
   -config string      配置文件路径 (默认: %s)
-  -input string       输入JSON文件路径（用于ollama/api模式分析已有数据）
+  -input string       输入JSON或Parquet文件路径（用于ollama/api模式分析已有数据）
+  -anonymous          匿名模式：不使用Cookie，以未登录状态爬取
+  -baseline           同时进行登录与未登录爬取，计算个性化程度
+  -format string      额外导出格式：csv|tsv|xlsx|jsonl|parquet（Tag统计、视频明细、共现Tag对）
+  -tui                终端面板：实时显示进度、重试、限流状态和 Top Tag
+  -prompt string      提示词：内置名称或 .tmpl 模板文件路径 (默认: default，见 prompts list)
+  -stream             流式输出：模型回答边生成边打印（Ctrl+C 可中断请求）
+  -no-cache           不使用模型回答缓存，强制重新请求模型
   -help               显示帮助信息`
 
 	HelpOllamaSection = `Ollama模式选项：
@@ -35,26 +93,66 @@ const (
 
 	HelpAPISection = `API模式选项：
   -api-endpoint string    远程API端点地址
-  -api-key string         远程API密钥`
+  -api-key string         远程API密钥
+  -api-model string       远程API模型名称 (默认: gpt-3.5-turbo)
+  -api-format string      远程API协议，即模型后端名称 (默认: openai)`
+
+	HelpGenerationSection = `生成参数（Ollama 与 API 模式通用，不指定时使用模型默认值）：
+  -temperature float      生成温度
+  -top-p float            核采样 top_p（0~1）
+  -max-tokens int         最大生成 token 数
+  -seed int               随机种子
+  -stop string            停止序列，可重复指定多次
+
+分块分析选项：
+  -map-reduce             完整 Tag 列表按上下文窗口分块总结，再合并为最终分析
+  -with-titles            分块分析时同时提供视频标题
+  -context-window int     模型上下文窗口 token 数 (默认: 8192)
+
+Tag 分类选项：
+  -categorize             让模型把 Tag 归入分类表（见配置 taxonomy）并按分类汇总，结果按 Tag 缓存
+
+语义聚类选项：
+  -cluster                用文本向量把含义相同的 Tag（如 我的世界/MC）聚类合并计数（见配置 clustering）`
 
 	HelpExamples = `示例：
   biliTagAnalyse -json                      # 仅生成JSON文件
   biliTagAnalyse -ollama                    # 使用Ollama分析新爬取的数据
   biliTagAnalyse -ollama -input data.json   # 使用Ollama分析已有JSON文件
-  biliTagAnalyse -api -api-endpoint https://api.example.com/v1/chat`
+  biliTagAnalyse -api -api-endpoint https://api.example.com/v1/chat/completions
+  biliTagAnalyse -api -api-format anthropic -api-key sk-ant-xxx  # 使用 Anthropic Messages API
+  biliTagAnalyse -json -baseline            # 同时爬取登录/未登录推荐流
+  biliTagAnalyse -json -format xlsx         # 额外导出Excel工作簿
+  biliTagAnalyse -provider mock -input data.json  # 使用离线 mock 后端验证分析流程
+  biliTagAnalyse -ollama -input data.json -prompt bubble-audit  # 使用内置提示词审计信息茧房
+  biliTagAnalyse compare 新号=results/new.json 游戏号=results/game.json`
 )
 
 const (
-	ErrMultipleModes    = "错误：只能指定一种运行模式"
-	ErrModePriority     = "模式优先级：json > ollama > api"
-	ErrOllamaURL        = "Ollama模式需要指定 -ollama-url"
-	ErrOllamaModel      = "Ollama模式需要指定 -ollama-model"
-	ErrAPIEndpoint      = "API模式需要指定 -api-endpoint"
+	ErrMultipleModes  = "错误：只能指定一种运行模式"
+	ErrModePriority   = "模式优先级：json > ollama > api"
+	ErrOllamaURL      = "Ollama模式需要指定 -ollama-url"
+	ErrOllamaModel    = "Ollama模式需要指定 -ollama-model"
+	ErrAPIEndpoint    = "API模式需要指定 -api-endpoint"
+	ErrUnknownCommand = "未知命令: %s"
+	ErrCompareInputs  = "compare 命令至少需要两个数据文件"
+	ErrCompareLabel   = "compare 的标签重复: %s"
+	ErrMergeInputs    = "merge 命令至少需要一个快照文件"
+	ErrUnknownFormat  = "不支持的导出格式: %s（可选 csv|tsv|xlsx|jsonl|parquet）"
+	ErrPromptsShow    = "prompts show 需要指定提示词名称"
+	ErrPromptsAction  = "未知的 prompts 操作: %s（可选 list|show）"
+	ErrCacheAction    = "未知的 cache 操作: %s（可选 stats|clear）"
+	ErrContextWindow  = "context_window 不能为负数: %d"
+	ErrTemperature    = "temperature 不能为负数: %g"
+	ErrTopP           = "top_p 需要在 (0, 1] 范围内: %g"
+	ErrMaxTokens      = "max_tokens 需要大于 0: %d"
+	ErrInvalidEnv     = "环境变量 %s 的值无效: %s"
 )
 
 const (
-	ModeDescJSON   = "JSON文件输出模式"
-	ModeDescOllama = "Ollama本地模型分析模式 (模型: %s, 地址: %s)"
-	ModeDescAPI    = "远程API调用模式 (端点: %s)"
-	ModeDescUnknown = "未知模式"
+	ModeDescJSON     = "JSON文件输出模式"
+	ModeDescOllama   = "Ollama本地模型分析模式 (模型: %s, 地址: %s)"
+	ModeDescAPI      = "远程API调用模式 (协议: %s, 端点: %s)"
+	ModeDescProvider = "模型后端分析模式 (后端: %s)"
+	ModeDescUnknown  = "未知模式"
 )
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GenerationParams 是发送给模型的生成参数，未设置（nil/空）的参数不会发送，由模型使用默认值。
+// 同一结构用于命令行参数、环境变量、config.json 顶层字段和 providers 配置块。
+type GenerationParams struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// Fill 用 defaults 补齐尚未设置的参数，已设置的参数保持不变
+func (p *GenerationParams) Fill(defaults GenerationParams) {
+	if p.Temperature == nil {
+		p.Temperature = defaults.Temperature
+	}
+	if p.TopP == nil {
+		p.TopP = defaults.TopP
+	}
+	if p.MaxTokens == nil {
+		p.MaxTokens = defaults.MaxTokens
+	}
+	if p.Seed == nil {
+		p.Seed = defaults.Seed
+	}
+	if len(p.Stop) == 0 {
+		p.Stop = defaults.Stop
+	}
+}
+
+func (p GenerationParams) IsZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.MaxTokens == nil && p.Seed == nil && len(p.Stop) == 0
+}
+
+func (p GenerationParams) String() string {
+	var parts []string
+	if p.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temperature=%g", *p.Temperature))
+	}
+	if p.TopP != nil {
+		parts = append(parts, fmt.Sprintf("top_p=%g", *p.TopP))
+	}
+	if p.MaxTokens != nil {
+		parts = append(parts, fmt.Sprintf("max_tokens=%d", *p.MaxTokens))
+	}
+	if p.Seed != nil {
+		parts = append(parts, fmt.Sprintf("seed=%d", *p.Seed))
+	}
+	if len(p.Stop) > 0 {
+		parts = append(parts, fmt.Sprintf("stop=%q", p.Stop))
+	}
+	if len(parts) == 0 {
+		return "模型默认"
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p GenerationParams) Validate() error {
+	if p.Temperature != nil && *p.Temperature < 0 {
+		return fmt.Errorf(ErrTemperature, *p.Temperature)
+	}
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		return fmt.Errorf(ErrTopP, *p.TopP)
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		return fmt.Errorf(ErrMaxTokens, *p.MaxTokens)
+	}
+	return nil
+}
+
+func floatSetter(target **float64) func(string) error {
+	return func(s string) error {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return err
+		}
+		*target = &v
+		return nil
+	}
+}
+
+func intSetter(target **int) func(string) error {
+	return func(s string) error {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		*target = &v
+		return nil
+	}
+}
+
+// generationFromEnv 读取 BILI_TEMPERATURE 等环境变量，BILI_STOP 以英文逗号分隔多个停止序列
+func generationFromEnv() (GenerationParams, error) {
+	var p GenerationParams
+	setters := []struct {
+		key string
+		set func(string) error
+	}{
+		{EnvTemperature, floatSetter(&p.Temperature)},
+		{EnvTopP, floatSetter(&p.TopP)},
+		{EnvMaxTokens, intSetter(&p.MaxTokens)},
+		{EnvSeed, intSetter(&p.Seed)},
+	}
+	for _, s := range setters {
+		value := os.Getenv(s.key)
+		if value == "" {
+			continue
+		}
+		if err := s.set(value); err != nil {
+			return p, fmt.Errorf(ErrInvalidEnv, s.key, value)
+		}
+	}
+	if value := os.Getenv(EnvStop); value != "" {
+		p.Stop = strings.Split(value, ",")
+	}
+	return p, nil
+}
+
+var flagGeneration GenerationParams
+
+func init() {
+	flag.Func("temperature", "生成温度（如 0.2；不指定时使用模型默认值）", floatSetter(&flagGeneration.Temperature))
+	flag.Func("top-p", "核采样 top_p（0~1）", floatSetter(&flagGeneration.TopP))
+	flag.Func("max-tokens", "最大生成 token 数（Ollama 对应 num_predict）", intSetter(&flagGeneration.MaxTokens))
+	flag.Func("seed", "随机种子，固定后结果可复现", intSetter(&flagGeneration.Seed))
+	flag.Func("stop", "停止序列，可重复指定多次", func(s string) error {
+		flagGeneration.Stop = append(flagGeneration.Stop, s)
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+)
+
+type MergeOptions struct {
+	Inputs []string
+	Output string
+}
+
+func ParseMerge(args []string) (*MergeOptions, error) {
+	fs := flag.NewFlagSet(CommandMerge, flag.ContinueOnError)
+	output := fs.String("output", DefaultMergeOutput, "合并后统计结果输出路径")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	opts := &MergeOptions{Inputs: fs.Args(), Output: *output}
+	if len(opts.Inputs) == 0 {
+		return nil, fmt.Errorf(ErrMergeInputs)
+	}
+
+	return opts, nil
+}
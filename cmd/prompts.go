@@ -0,0 +1,33 @@
+package cmd
+
+import "fmt"
+
+const (
+	PromptsList = "list"
+	PromptsShow = "show"
+)
+
+type PromptsOptions struct {
+	Action string
+	Name   string
+}
+
+// ParsePrompts 解析 prompts 子命令：list 列出内置提示词，show 输出模板原文
+func ParsePrompts(args []string) (*PromptsOptions, error) {
+	opts := &PromptsOptions{Action: PromptsList}
+	if len(args) > 0 {
+		opts.Action = args[0]
+	}
+
+	switch opts.Action {
+	case PromptsList:
+	case PromptsShow:
+		if len(args) < 2 {
+			return nil, fmt.Errorf(ErrPromptsShow)
+		}
+		opts.Name = args[1]
+	default:
+		return nil, fmt.Errorf(ErrPromptsAction, opts.Action)
+	}
+	return opts, nil
+}
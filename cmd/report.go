@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"flag"
+)
+
+type ReportOptions struct {
+	Input    string
+	Output   string
+	Markdown string
+	Template string
+	TopN     int
+}
+
+func ParseReport(args []string) (*ReportOptions, error) {
+	fs := flag.NewFlagSet(CommandReport, flag.ContinueOnError)
+	output := fs.String("output", DefaultReportOutput, "HTML报告输出路径")
+	markdown := fs.String("markdown", "", "同时生成Markdown报告的输出路径")
+	template := fs.String("template", "", "自定义Markdown报告模板（text/template）")
+	topN := fs.Int("top", DefaultReportTopN, "柱状图展示的Tag数量")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	opts := &ReportOptions{
+		Input:    DefaultReportInput,
+		Output:   *output,
+		Markdown: *markdown,
+		Template: *template,
+		TopN:     *topN,
+	}
+	if fs.NArg() > 0 {
+		opts.Input = fs.Arg(0)
+	}
+
+	return opts, nil
+}
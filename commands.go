@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"biliTagAnalyse/analyzer"
+	"biliTagAnalyse/cmd"
+	"biliTagAnalyse/config"
+	"biliTagAnalyse/report"
+	"biliTagAnalyse/statistics"
+)
+
+func runCommand(opts *cmd.Options) error {
+	switch opts.Command {
+	case cmd.CommandCompare:
+		return runCompare(opts.ConfigPath, opts.CommandArgs)
+	case cmd.CommandMerge:
+		return runMerge(opts.CommandArgs)
+	case cmd.CommandReport:
+		return runReport(opts.CommandArgs)
+	case cmd.CommandPrompts:
+		return runPrompts(opts.CommandArgs)
+	case cmd.CommandCache:
+		return runCache(opts.ConfigPath, opts.CommandArgs)
+	default:
+		return fmt.Errorf(cmd.ErrUnknownCommand, opts.Command)
+	}
+}
+
+// runCompare 对比多个数据集，原始视频数据按配置文件中的 tag_filter 过滤，与写入统计结果时的口径一致
+func runCompare(configPath string, args []string) error {
+	compareOpts, err := cmd.ParseCompare(args)
+	if err != nil {
+		return err
+	}
+
+	var filterCfg statistics.TagFilterConfig
+	if cfg, err := config.LoadConfig(configPath); err == nil {
+		filterCfg = cfg.TagFilter
+	} else {
+		log.Printf("读取配置失败，原始视频数据使用默认过滤规则: %v", err)
+	}
+	tagFilter, err := statistics.NewTagFilter(filterCfg)
+	if err != nil {
+		return fmt.Errorf("加载Tag过滤规则失败: %w", err)
+	}
+
+	var datasets []statistics.LabeledStats
+	labels := make(map[string]string)
+	for _, input := range compareOpts.Inputs {
+		log.Printf("加载数据集: %s", input.Path)
+		stats, err := statistics.LoadDataset(input.Path, tagFilter)
+		if err != nil {
+			return fmt.Errorf("加载 %s 失败: %w", input.Path, err)
+		}
+
+		label := input.Label
+		if label == "" {
+			label = stats.Label
+		}
+		if label == "" {
+			label = strings.TrimSuffix(filepath.Base(input.Path), filepath.Ext(input.Path))
+		}
+		// 对比结果按标签索引，重名的数据集会互相覆盖
+		if other, ok := labels[label]; ok {
+			return fmt.Errorf("%s 与 %s 的标签重复: %s，请用 标签=路径 指定不同的标签", other, input.Path, label)
+		}
+		labels[label] = input.Path
+		datasets = append(datasets, statistics.LabeledStats{Label: label, Stats: stats})
+	}
+
+	result := statistics.CompareFeeds(datasets, compareOpts.TopK)
+	if err := statistics.SaveCompareResult(result, compareOpts.Output); err != nil {
+		return err
+	}
+
+	printCompareResult(result)
+	fmt.Printf("\n对比结果已保存到: %s\n", compareOpts.Output)
+	return nil
+}
+
+func runMerge(args []string) error {
+	mergeOpts, err := cmd.ParseMerge(args)
+	if err != nil {
+		return err
+	}
+
+	agg := statistics.NewAggregator(nil)
+	for _, path := range mergeOpts.Inputs {
+		log.Printf("合并快照: %s", path)
+		snap, err := statistics.LoadSnapshot(path)
+		if err != nil {
+			return err
+		}
+		if err := agg.Merge(snap); err != nil {
+			return fmt.Errorf("合并 %s 失败: %w", path, err)
+		}
+	}
+
+	result := agg.Result()
+	result.Diversity = agg.Diversity(nil)
+	result.Sampling = agg.Sampling(statistics.DefaultStabilityTopK)
+	if err := statistics.SaveResults(result, mergeOpts.Output); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n已合并 %d 个快照，共 %d 个视频、%d 个不同Tag\n", len(mergeOpts.Inputs), result.TotalVideos, result.TotalTags)
+	fmt.Printf("结果已保存到: %s\n", mergeOpts.Output)
+	return nil
+}
+
+func runReport(args []string) error {
+	reportOpts, err := cmd.ParseReport(args)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("加载分析结果: %s", reportOpts.Input)
+	result, err := analyzer.LoadAnalysisResult(reportOpts.Input)
+	if err != nil {
+		return fmt.Errorf("加载 %s 失败: %w", reportOpts.Input, err)
+	}
+
+	if err := report.WriteHTML(result, reportOpts.Output, reportOpts.TopN); err != nil {
+		return err
+	}
+
+	fmt.Printf("报告已生成: %s\n", reportOpts.Output)
+
+	if reportOpts.Markdown != "" {
+		if err := report.WriteMarkdown(result, reportOpts.Markdown, reportOpts.Template, reportOpts.TopN); err != nil {
+			return err
+		}
+		fmt.Printf("Markdown报告已生成: %s\n", reportOpts.Markdown)
+	}
+	return nil
+}
+
+func runPrompts(args []string) error {
+	promptsOpts, err := cmd.ParsePrompts(args)
+	if err != nil {
+		return err
+	}
+
+	if promptsOpts.Action == cmd.PromptsShow {
+		text, _, err := analyzer.LoadPromptTemplate(promptsOpts.Name)
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+		return nil
+	}
+
+	presets, err := analyzer.PromptPresets()
+	if err != nil {
+		return err
+	}
+	fmt.Println("内置提示词（-prompt 名称 使用，prompts show 名称 查看模板）:")
+	for _, preset := range presets {
+		marker := " "
+		if preset.Name == analyzer.DefaultPrompt {
+			marker = "*"
+		}
+		fmt.Printf("%s %-18s %s\n", marker, preset.Name, preset.Description)
+	}
+	fmt.Println("\n也可以指定自定义模板文件：-prompt my_prompt.tmpl（Go text/template，可使用 .Stats、.Videos、.TopTags）")
+	return nil
+}
+
+func printMatrix(title string, labels []string, matrix [][]float64) {
+	fmt.Printf("\n%s:\n", title)
+	fmt.Printf("%-12s", "")
+	for _, label := range labels {
+		fmt.Printf("%12s", label)
+	}
+	fmt.Println()
+	for i, row := range matrix {
+		fmt.Printf("%-12s", labels[i])
+		for _, v := range row {
+			fmt.Printf("%12.3f", v)
+		}
+		fmt.Println()
+	}
+}
+
+func printCompareResult(result *statistics.CompareResult) {
+	fmt.Println("\n=== 推荐流对比 ===")
+	printMatrix("Tag 集合 Jaccard 相似度", result.Accounts, result.Jaccard)
+	printMatrix("Tag 频次余弦相似度", result.Accounts, result.Cosine)
+	printMatrix(fmt.Sprintf("Top %d Spearman 秩相关", result.TopK), result.Accounts, result.Spearman)
+	printMatrix(fmt.Sprintf("Top %d Kendall τ-b", result.TopK), result.Accounts, result.Kendall)
+
+	fmt.Println("\n各账号独有 Tag:")
+	for _, label := range result.Accounts {
+		var tags []string
+		for i, stat := range result.UniqueTags[label] {
+			if i >= 10 {
+				break
+			}
+			tags = append(tags, fmt.Sprintf("%s(%d)", stat.Tag, stat.Count))
+		}
+		fmt.Printf("  %s: %s\n", label, strings.Join(tags, ", "))
+	}
+
+	fmt.Println("\n并排对比 (Top 20):")
+	fmt.Printf("%-6s", "排名")
+	for _, label := range result.Accounts {
+		fmt.Printf("%-24s", label)
+	}
+	fmt.Println()
+	for i, row := range result.SideBySide {
+		if i >= 20 {
+			break
+		}
+		fmt.Printf("%-6d", row.Rank)
+		for _, entry := range row.Entries {
+			cell := ""
+			if entry.Tag != "" {
+				cell = fmt.Sprintf("%s(%d)", entry.Tag, entry.Count)
+			}
+			fmt.Printf("%-24s", cell)
+		}
+		fmt.Println()
+	}
+}
+
+// runCache 管理模型回答缓存，缓存目录和有效期取自配置文件，-dir 可覆盖目录
+func runCache(configPath string, args []string) error {
+	cacheOpts, err := cmd.ParseCache(args)
+	if err != nil {
+		return err
+	}
+
+	var cacheCfg analyzer.ResponseCacheConfig
+	if cfg, err := config.LoadConfig(configPath); err == nil {
+		cacheCfg = cfg.ResponseCache
+	} else {
+		log.Printf("读取配置失败，使用默认缓存设置: %v", err)
+	}
+	if cacheOpts.Dir != "" {
+		cacheCfg.Dir = cacheOpts.Dir
+	}
+	cache := analyzer.NewResponseCache(cacheCfg)
+
+	if cacheOpts.Action == cmd.CacheClear {
+		removed, err := cache.Clear(cacheOpts.Expired)
+		if err != nil {
+			return err
+		}
+		if cacheOpts.Expired {
+			fmt.Printf("已删除 %d 条过期的缓存记录\n", removed)
+		} else {
+			fmt.Printf("已删除 %d 条缓存记录\n", removed)
+		}
+		return nil
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("缓存目录: %s\n", stats.Dir)
+	fmt.Printf("有效期: %.0f 小时\n", stats.TTL.Hours())
+	fmt.Printf("记录数: %d (已过期 %d)\n", stats.Entries, stats.Expired)
+	fmt.Printf("占用空间: %.1f KB\n", float64(stats.Bytes)/1024)
+	if stats.Entries > 0 {
+		fmt.Printf("最早: %s\n", stats.Oldest.Local().Format("2006-01-02 15:04:05"))
+		fmt.Printf("最新: %s\n", stats.Newest.Local().Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 
+	"biliTagAnalyse/analyzer"
 	"biliTagAnalyse/cmd"
+	"biliTagAnalyse/statistics"
 )
 
 type Config struct {
@@ -23,6 +25,45 @@ type Config struct {
 	OllamaModel     string `json:"ollama_model"`
 	APIEndpoint     string `json:"api_endpoint"`
 	APIKey          string `json:"api_key"`
+	APIModel        string `json:"api_model"`
+	APIFormat       string `json:"api_format"`
+	HistoryFile     string `json:"history_file"`
+	RawOutputFile   string `json:"raw_output_file"`
+	SnapshotFile    string `json:"snapshot_file"`
+	ReportFile      string `json:"report_file"`
+	ReportTemplate  string `json:"report_template"`
+	AccountLabel    string `json:"account_label"`
+	Anonymous       bool   `json:"anonymous"`
+	CrawlBaseline   bool   `json:"crawl_baseline"`
+	TUI             bool   `json:"tui"`
+	Stream          bool   `json:"stream"`
+	MapReduce       bool   `json:"map_reduce"`
+	WithTitles      bool   `json:"with_titles"`
+	ContextWindow   int    `json:"context_window"`
+	Prompt          string `json:"prompt"`
+	Categorize      bool   `json:"categorize"`
+	Cluster         bool   `json:"cluster"`
+
+	AutoStop          bool    `json:"auto_stop"`
+	AutoStopThreshold float64 `json:"auto_stop_threshold"`
+	AutoStopMinRounds int     `json:"auto_stop_min_rounds"`
+
+	TagFilter statistics.TagFilterConfig `json:"tag_filter"`
+
+	// Taxonomy 为 -categorize 使用的分类表、缓存文件等参数
+	Taxonomy analyzer.TaxonomyConfig `json:"taxonomy"`
+
+	// Clustering 为 -cluster 使用的相似度阈值、向量缓存等参数
+	Clustering analyzer.ClusterConfig `json:"clustering"`
+
+	// ResponseCache 为模型回答缓存的目录和有效期
+	ResponseCache analyzer.ResponseCacheConfig `json:"response_cache"`
+
+	// 生成参数以 temperature、top_p 等顶层字段出现在 config.json 中
+	cmd.GenerationParams
+
+	// Providers 为各模型后端的独立配置块，键为后端名称，内容由对应后端自行解析
+	Providers map[string]json.RawMessage `json:"providers"`
 }
 
 func ResolveConfigPath(path string) string {
@@ -60,10 +101,6 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
-	if cfg.Cookie == "" || cfg.Cookie == "你的B站Cookie" {
-		return nil, fmt.Errorf("请在 config.json 中设置有效的 B站 Cookie")
-	}
-
 	if cfg.CrawlCount <= 0 {
 		cfg.CrawlCount = 1
 	}
@@ -85,6 +122,21 @@ func LoadConfig(path string) (*Config, error) {
 	if cfg.OutputFile == "" {
 		cfg.OutputFile = "results/tags_stats.json"
 	}
+	if cfg.AutoStopThreshold <= 0 {
+		cfg.AutoStopThreshold = 0.05
+	}
+	if cfg.AutoStopMinRounds <= 0 {
+		cfg.AutoStopMinRounds = 3
+	}
+	if cfg.RawOutputFile == "" {
+		cfg.RawOutputFile = "results/raw_videos.jsonl"
+	}
+	if cfg.ReportFile == "" {
+		cfg.ReportFile = "results/report.md"
+	}
+	if cfg.HistoryFile == "" {
+		cfg.HistoryFile = "results/history.jsonl"
+	}
 	if cfg.RunMode == "" {
 		cfg.RunMode = "once"
 	}
@@ -97,3 +149,13 @@ func LoadConfig(path string) (*Config, error) {
 
 	return &cfg, nil
 }
+
+func (c *Config) Validate() error {
+	if c.Anonymous {
+		return nil
+	}
+	if c.Cookie == "" || c.Cookie == "你的B站Cookie" || c.Cookie == "Your bilibili Cookie" {
+		return fmt.Errorf("请在 config.json 中设置有效的 B站 Cookie，或使用 -anonymous 以未登录状态爬取")
+	}
+	return nil
+}
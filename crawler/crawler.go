@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"biliTagAnalyse/parser"
 	"biliTagAnalyse/utils"
 
 	"golang.org/x/net/html"
@@ -30,6 +31,11 @@ func NewHomepageCrawler(cookie string, retryCount, retryDelay, requestInterval,
 	}
 }
 
+// SetObserver 设置请求事件的观察者，用于终端面板展示进度
+func (c *HomepageCrawler) SetObserver(observer utils.RequestObserver) {
+	c.client.Observer = observer
+}
+
 func (c *HomepageCrawler) CrawlHomepage() ([]string, error) {
 	log.Println("正在爬取 B站 首页...")
 
@@ -98,6 +104,7 @@ func extractVideoLinks(htmlContent string) []string {
 
 type VideoCrawler struct {
 	client          *utils.HTTPClient
+	parser          *parser.VideoParser
 	retryCount      int
 	retryDelay      int
 	requestInterval int
@@ -107,6 +114,7 @@ type VideoCrawler struct {
 func NewVideoCrawler(cookie string, retryCount, retryDelay, requestInterval, maxConcurrent int) *VideoCrawler {
 	return &VideoCrawler{
 		client:          utils.NewHTTPClient(cookie),
+		parser:          parser.NewVideoParser(),
 		retryCount:      retryCount,
 		retryDelay:      retryDelay,
 		requestInterval: requestInterval,
@@ -114,11 +122,34 @@ func NewVideoCrawler(cookie string, retryCount, retryDelay, requestInterval, max
 	}
 }
 
+// SetObserver 设置请求事件的观察者，用于终端面板展示进度
+func (c *VideoCrawler) SetObserver(observer utils.RequestObserver) {
+	c.client.Observer = observer
+}
+
 type VideoInfo struct {
-	Link   string
-	Title  string
-	Author string
-	Tags   []string
+	Round     int              `json:"round"`
+	Index     int              `json:"index"`
+	Link      string           `json:"link"`
+	Title     string           `json:"title"`
+	Author    string           `json:"author"`
+	AuthorMid int64            `json:"author_mid"`
+	Tags      []string         `json:"tags"`
+	TagIDs    map[string]int64 `json:"tag_ids,omitempty"`
+	TID       int              `json:"tid"`
+	TName     string           `json:"tname"`
+	MainTID   int              `json:"main_tid"`
+	MainName  string           `json:"main_name"`
+
+	Views     int64 `json:"views"`
+	Likes     int64 `json:"likes"`
+	Coins     int64 `json:"coins"`
+	Favorites int64 `json:"favorites"`
+	Shares    int64 `json:"shares"`
+	Replies   int64 `json:"replies"`
+	Danmaku   int64 `json:"danmaku"`
+
+	CrawledAt string `json:"crawled_at,omitempty"`
 }
 
 func extractTagsFromHTML(htmlContent string) []string {
@@ -167,13 +198,13 @@ func getText(n *html.Node) string {
 	return s
 }
 
-func (c *VideoCrawler) CrawlVideosConcurrently(links []string) []*VideoInfo {
+// StreamVideos 并发爬取视频，每解析完一个视频就发送到 out，全部完成后返回（不关闭 out）。
+// 视频到达顺序取决于请求耗时，VideoInfo.Index 记录视频在 links 中的位置。
+func (c *VideoCrawler) StreamVideos(links []string, round int, out chan<- *VideoInfo) {
 	log.Printf("开始并发爬取 %d 个视频...", len(links))
 
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, c.maxConcurrent)
-	var mu sync.Mutex
-	results := make([]*VideoInfo, 0, len(links))
 
 	for i, link := range links {
 		wg.Add(1)
@@ -191,18 +222,44 @@ func (c *VideoCrawler) CrawlVideosConcurrently(links []string) []*VideoInfo {
 				return
 			}
 
-			tags := extractTagsFromHTML(string(body))
-
-			mu.Lock()
-			results = append(results, &VideoInfo{
-				Link: videoLink,
-				Tags: tags,
-			})
-			mu.Unlock()
+			video := c.parseVideo(videoLink, string(body))
+			video.Round = round
+			video.Index = id
+			out <- video
 		}(i, link)
 	}
 
 	wg.Wait()
+}
+
+func (c *VideoCrawler) parseVideo(link, htmlContent string) *VideoInfo {
+	tid, tname := c.parser.ExtractPartition(htmlContent)
+	mainPartition, subPartition := ResolvePartition(tid, tname)
+	stat := c.parser.ExtractStat(htmlContent)
 
-	return results
+	mid, author := c.parser.ExtractOwner(htmlContent)
+	if author == "" {
+		author = c.parser.ExtractAuthor(htmlContent)
+	}
+
+	return &VideoInfo{
+		Link:      link,
+		Title:     c.parser.ExtractTitle(htmlContent),
+		Author:    author,
+		AuthorMid: mid,
+		Tags:      extractTagsFromHTML(htmlContent),
+		TagIDs:    c.parser.ExtractTagIDs(htmlContent),
+		TID:       subPartition.TID,
+		TName:     subPartition.Name,
+		MainTID:   mainPartition.TID,
+		MainName:  mainPartition.Name,
+		Views:     stat.View,
+		Likes:     stat.Like,
+		Coins:     stat.Coin,
+		Favorites: stat.Favorite,
+		Shares:    stat.Share,
+		Replies:   stat.Reply,
+		Danmaku:   stat.Danmaku,
+		CrawledAt: time.Now().Format(time.RFC3339),
+	}
 }
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type VideoWriter struct {
+	file *os.File
+	w    *bufio.Writer
+	enc  *json.Encoder
+}
+
+func NewVideoWriter(path string) (*VideoWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建原始数据文件失败: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &VideoWriter{file: file, w: w, enc: enc}, nil
+}
+
+func (vw *VideoWriter) Write(video *VideoInfo) error {
+	if err := vw.enc.Encode(video); err != nil {
+		return fmt.Errorf("写入原始数据失败: %w", err)
+	}
+	return nil
+}
+
+func (vw *VideoWriter) Close() error {
+	if err := vw.w.Flush(); err != nil {
+		vw.file.Close()
+		return fmt.Errorf("写入原始数据失败: %w", err)
+	}
+	return vw.file.Close()
+}
+
+func LoadVideos(path string) ([][]*VideoInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取原始数据文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var allVideos [][]*VideoInfo
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var video VideoInfo
+		if err := json.Unmarshal(line, &video); err != nil {
+			return nil, fmt.Errorf("解析原始数据失败: %w", err)
+		}
+
+		round := video.Round
+		if round <= 0 {
+			round = 1
+		}
+		for len(allVideos) < round {
+			allVideos = append(allVideos, nil)
+		}
+		allVideos[round-1] = append(allVideos[round-1], &video)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取原始数据文件失败: %w", err)
+	}
+
+	return allVideos, nil
+}
@@ -0,0 +1,198 @@
+package crawler
+
+type Partition struct {
+	TID    int
+	Name   string
+	Parent int
+}
+
+const UnknownPartitionName = "未知分区"
+
+// 离线分区表，来源于 B站 公开的 tid 分区列表。
+var partitionTable = map[int]Partition{
+	1:   {TID: 1, Name: "动画"},
+	24:  {TID: 24, Name: "MAD·AMV", Parent: 1},
+	25:  {TID: 25, Name: "MMD·3D", Parent: 1},
+	47:  {TID: 47, Name: "短片·手书·配音", Parent: 1},
+	210: {TID: 210, Name: "手办·模玩", Parent: 1},
+	86:  {TID: 86, Name: "特摄", Parent: 1},
+	253: {TID: 253, Name: "动漫杂谈", Parent: 1},
+	27:  {TID: 27, Name: "综合", Parent: 1},
+
+	13:  {TID: 13, Name: "番剧"},
+	33:  {TID: 33, Name: "连载动画", Parent: 13},
+	32:  {TID: 32, Name: "完结动画", Parent: 13},
+	51:  {TID: 51, Name: "资讯", Parent: 13},
+	152: {TID: 152, Name: "官方延伸", Parent: 13},
+
+	167: {TID: 167, Name: "国创"},
+	153: {TID: 153, Name: "国产动画", Parent: 167},
+	168: {TID: 168, Name: "国产原创相关", Parent: 167},
+	169: {TID: 169, Name: "布袋戏", Parent: 167},
+	195: {TID: 195, Name: "动态漫·广播剧", Parent: 167},
+	170: {TID: 170, Name: "资讯", Parent: 167},
+
+	3:   {TID: 3, Name: "音乐"},
+	28:  {TID: 28, Name: "原创音乐", Parent: 3},
+	31:  {TID: 31, Name: "翻唱", Parent: 3},
+	30:  {TID: 30, Name: "VOCALOID·UTAU", Parent: 3},
+	59:  {TID: 59, Name: "演奏", Parent: 3},
+	193: {TID: 193, Name: "MV", Parent: 3},
+	29:  {TID: 29, Name: "音乐现场", Parent: 3},
+	130: {TID: 130, Name: "音乐综合", Parent: 3},
+	243: {TID: 243, Name: "乐评盘点", Parent: 3},
+	244: {TID: 244, Name: "音乐教学", Parent: 3},
+
+	129: {TID: 129, Name: "舞蹈"},
+	20:  {TID: 20, Name: "宅舞", Parent: 129},
+	154: {TID: 154, Name: "舞蹈综合", Parent: 129},
+	156: {TID: 156, Name: "舞蹈教程", Parent: 129},
+	198: {TID: 198, Name: "街舞", Parent: 129},
+	199: {TID: 199, Name: "明星舞蹈", Parent: 129},
+	200: {TID: 200, Name: "国风舞蹈", Parent: 129},
+	255: {TID: 255, Name: "手势·网红舞", Parent: 129},
+
+	4:   {TID: 4, Name: "游戏"},
+	17:  {TID: 17, Name: "单机游戏", Parent: 4},
+	171: {TID: 171, Name: "电子竞技", Parent: 4},
+	172: {TID: 172, Name: "手机游戏", Parent: 4},
+	65:  {TID: 65, Name: "网络游戏", Parent: 4},
+	173: {TID: 173, Name: "桌游棋牌", Parent: 4},
+	121: {TID: 121, Name: "GMV", Parent: 4},
+	136: {TID: 136, Name: "音游", Parent: 4},
+	19:  {TID: 19, Name: "Mugen", Parent: 4},
+
+	36:  {TID: 36, Name: "知识"},
+	201: {TID: 201, Name: "科学科普", Parent: 36},
+	124: {TID: 124, Name: "社科·法律·心理", Parent: 36},
+	228: {TID: 228, Name: "人文历史", Parent: 36},
+	207: {TID: 207, Name: "财经商业", Parent: 36},
+	208: {TID: 208, Name: "校园学习", Parent: 36},
+	209: {TID: 209, Name: "职业职场", Parent: 36},
+	229: {TID: 229, Name: "设计·创意", Parent: 36},
+	122: {TID: 122, Name: "野生技能协会", Parent: 36},
+
+	188: {TID: 188, Name: "科技"},
+	95:  {TID: 95, Name: "数码", Parent: 188},
+	230: {TID: 230, Name: "软件应用", Parent: 188},
+	231: {TID: 231, Name: "计算机技术", Parent: 188},
+	232: {TID: 232, Name: "科工机械", Parent: 188},
+	233: {TID: 233, Name: "极客DIY", Parent: 188},
+
+	234: {TID: 234, Name: "运动"},
+	235: {TID: 235, Name: "篮球", Parent: 234},
+	249: {TID: 249, Name: "足球", Parent: 234},
+	164: {TID: 164, Name: "健身", Parent: 234},
+	236: {TID: 236, Name: "竞技体育", Parent: 234},
+	237: {TID: 237, Name: "运动文化", Parent: 234},
+	238: {TID: 238, Name: "运动综合", Parent: 234},
+
+	223: {TID: 223, Name: "汽车"},
+	245: {TID: 245, Name: "赛车", Parent: 223},
+	246: {TID: 246, Name: "改装玩车", Parent: 223},
+	247: {TID: 247, Name: "新能源车", Parent: 223},
+	248: {TID: 248, Name: "房车", Parent: 223},
+	240: {TID: 240, Name: "摩托车", Parent: 223},
+	227: {TID: 227, Name: "购车攻略", Parent: 223},
+	176: {TID: 176, Name: "汽车生活", Parent: 223},
+
+	160: {TID: 160, Name: "生活"},
+	138: {TID: 138, Name: "搞笑", Parent: 160},
+	250: {TID: 250, Name: "出行", Parent: 160},
+	251: {TID: 251, Name: "三农", Parent: 160},
+	239: {TID: 239, Name: "家居房产", Parent: 160},
+	161: {TID: 161, Name: "手工", Parent: 160},
+	162: {TID: 162, Name: "绘画", Parent: 160},
+	21:  {TID: 21, Name: "日常", Parent: 160},
+
+	211: {TID: 211, Name: "美食"},
+	76:  {TID: 76, Name: "美食制作", Parent: 211},
+	212: {TID: 212, Name: "美食侦探", Parent: 211},
+	213: {TID: 213, Name: "美食测评", Parent: 211},
+	214: {TID: 214, Name: "田园美食", Parent: 211},
+	215: {TID: 215, Name: "美食记录", Parent: 211},
+
+	217: {TID: 217, Name: "动物圈"},
+	218: {TID: 218, Name: "喵星人", Parent: 217},
+	219: {TID: 219, Name: "汪星人", Parent: 217},
+	222: {TID: 222, Name: "小宠异宠", Parent: 217},
+	221: {TID: 221, Name: "野生动物", Parent: 217},
+	220: {TID: 220, Name: "动物二创", Parent: 217},
+	75:  {TID: 75, Name: "动物综合", Parent: 217},
+
+	119: {TID: 119, Name: "鬼畜"},
+	22:  {TID: 22, Name: "鬼畜调教", Parent: 119},
+	26:  {TID: 26, Name: "音MAD", Parent: 119},
+	126: {TID: 126, Name: "人力VOCALOID", Parent: 119},
+	216: {TID: 216, Name: "鬼畜剧场", Parent: 119},
+	127: {TID: 127, Name: "教程演示", Parent: 119},
+
+	155: {TID: 155, Name: "时尚"},
+	157: {TID: 157, Name: "美妆护肤", Parent: 155},
+	252: {TID: 252, Name: "仿妆cos", Parent: 155},
+	158: {TID: 158, Name: "穿搭", Parent: 155},
+	159: {TID: 159, Name: "时尚潮流", Parent: 155},
+
+	202: {TID: 202, Name: "资讯"},
+	203: {TID: 203, Name: "热点", Parent: 202},
+	204: {TID: 204, Name: "环球", Parent: 202},
+	205: {TID: 205, Name: "社会", Parent: 202},
+	206: {TID: 206, Name: "综合", Parent: 202},
+
+	5:   {TID: 5, Name: "娱乐"},
+	71:  {TID: 71, Name: "综艺", Parent: 5},
+	241: {TID: 241, Name: "娱乐杂谈", Parent: 5},
+	242: {TID: 242, Name: "粉丝创作", Parent: 5},
+	137: {TID: 137, Name: "明星综合", Parent: 5},
+
+	181: {TID: 181, Name: "影视"},
+	182: {TID: 182, Name: "影视杂谈", Parent: 181},
+	183: {TID: 183, Name: "影视剪辑", Parent: 181},
+	85:  {TID: 85, Name: "小剧场", Parent: 181},
+	184: {TID: 184, Name: "预告·资讯", Parent: 181},
+
+	177: {TID: 177, Name: "纪录片"},
+	37:  {TID: 37, Name: "人文·历史", Parent: 177},
+	178: {TID: 178, Name: "科学·探索·自然", Parent: 177},
+	179: {TID: 179, Name: "军事", Parent: 177},
+	180: {TID: 180, Name: "社会·美食·旅行", Parent: 177},
+
+	23:  {TID: 23, Name: "电影"},
+	147: {TID: 147, Name: "华语电影", Parent: 23},
+	145: {TID: 145, Name: "欧美电影", Parent: 23},
+	146: {TID: 146, Name: "日本电影", Parent: 23},
+	83:  {TID: 83, Name: "其他国家", Parent: 23},
+
+	11:  {TID: 11, Name: "电视剧"},
+	185: {TID: 185, Name: "国产剧", Parent: 11},
+	187: {TID: 187, Name: "海外剧", Parent: 11},
+}
+
+func LookupPartition(tid int) (Partition, bool) {
+	p, ok := partitionTable[tid]
+	return p, ok
+}
+
+// ResolvePartition 根据子分区 tid 返回主分区和子分区，页面给出的 tname 优先于离线表中的名称。
+func ResolvePartition(tid int, tname string) (main Partition, sub Partition) {
+	sub = Partition{TID: tid, Name: tname}
+
+	p, ok := partitionTable[tid]
+	if !ok {
+		if sub.Name == "" && tid != 0 {
+			sub.Name = UnknownPartitionName
+		}
+		return Partition{Name: UnknownPartitionName}, sub
+	}
+
+	if sub.Name == "" {
+		sub.Name = p.Name
+	}
+	if p.Parent == 0 {
+		return p, sub
+	}
+
+	main = partitionTable[p.Parent]
+	sub.Parent = main.TID
+	return main, sub
+}
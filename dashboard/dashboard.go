@@ -0,0 +1,261 @@
+// Package dashboard 在终端中实时展示多轮爬取的进度，stdout 不是终端时由调用方退回普通日志。
+package dashboard
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"biliTagAnalyse/statistics"
+)
+
+const (
+	refreshInterval = 250 * time.Millisecond
+	topTagCount     = 10
+	logLines        = 6
+	barWidth        = 24
+)
+
+// Session 记录单个账号（登录 / 未登录基线）当前轮次的爬取状态，实现 utils.RequestObserver
+type Session struct {
+	mu sync.Mutex
+
+	label string
+	top   func(n int) []statistics.TagStat
+
+	total    int
+	videos   int
+	inFlight int
+	retries  int
+	errors   int
+
+	rateLimitedUntil time.Time
+	rateLimitHits    int
+}
+
+func (s *Session) RequestStarted(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight++
+}
+
+func (s *Session) RequestFinished(url string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	if err != nil {
+		s.errors++
+	}
+}
+
+func (s *Session) RequestRetry(url string, attempt int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+}
+
+func (s *Session) RateLimited(until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimitHits++
+	if until.After(s.rateLimitedUntil) {
+		s.rateLimitedUntil = until
+	}
+}
+
+// StartRound 重置本轮进度，links 为本轮要爬取的视频数
+func (s *Session) StartRound(links int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total = links
+	s.videos = 0
+}
+
+func (s *Session) VideoDone() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.videos++
+}
+
+type Dashboard struct {
+	mu sync.Mutex
+
+	out         io.Writer
+	sessions    []*Session
+	totalRounds int
+	round       int
+	started     time.Time
+	nextRound   time.Time
+	logs        []string
+	partial     []byte
+
+	logOutput io.Writer
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func New(out io.Writer, totalRounds int) *Dashboard {
+	return &Dashboard{
+		out:         out,
+		totalRounds: totalRounds,
+		started:     time.Now(),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// AddSession 注册一个账号，top 用于实时读取当前的 Top Tag（通常是 Aggregator.TopTags）
+func (d *Dashboard) AddSession(label string, top func(n int) []statistics.TagStat) *Session {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := &Session{label: label, top: top}
+	d.sessions = append(d.sessions, s)
+	return s
+}
+
+func (d *Dashboard) SetRound(round int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.round = round
+	d.nextRound = time.Time{}
+}
+
+// WaitUntil 设置下一轮开始的时间，面板显示倒计时
+func (d *Dashboard) WaitUntil(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextRound = t
+}
+
+// Write 接管标准 log 输出，只保留最近几行显示在面板底部，避免日志打乱画面
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.partial = append(d.partial, p...)
+	for {
+		i := bytes.IndexByte(d.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSpace(string(d.partial[:i]))
+		d.partial = d.partial[i+1:]
+		if line == "" {
+			continue
+		}
+		d.logs = append(d.logs, line)
+		if len(d.logs) > logLines {
+			d.logs = d.logs[len(d.logs)-logLines:]
+		}
+	}
+	return len(p), nil
+}
+
+// Start 接管日志并开始定时刷新
+func (d *Dashboard) Start() {
+	d.logOutput = log.Writer()
+	log.SetOutput(d)
+	fmt.Fprint(d.out, hideCursor+clearScreen)
+
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			d.render()
+			select {
+			case <-ticker.C:
+			case <-d.stop:
+				d.render()
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止刷新，恢复光标和日志输出；面板最后一帧保留在屏幕上
+func (d *Dashboard) Stop() {
+	close(d.stop)
+	<-d.done
+	fmt.Fprint(d.out, showCursor)
+	log.SetOutput(d.logOutput)
+}
+
+func (d *Dashboard) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString(clearLine + "\n")
+	}
+
+	elapsed := time.Since(d.started).Truncate(time.Second)
+	line("%sB站推荐 Tag 爬虫%s  第 %d/%d 轮  %s  已用时 %s",
+		colorBold+colorPink, colorReset, d.round, d.totalRounds, bar(d.round, d.totalRounds, 10), elapsed)
+
+	if !d.nextRound.IsZero() {
+		remaining := time.Until(d.nextRound).Truncate(time.Second)
+		line("%s下一轮将在 %s 后开始%s", colorCyan, max(remaining, 0), colorReset)
+	} else {
+		line("")
+	}
+	line("")
+
+	for _, s := range d.sessions {
+		s.mu.Lock()
+		rateLimit := colorGreen + "正常" + colorReset
+		if wait := time.Until(s.rateLimitedUntil); wait > 0 {
+			rateLimit = fmt.Sprintf("%s限流中，预计 %s 后解除%s", colorRed, wait.Truncate(time.Second)+time.Second, colorReset)
+		} else if s.rateLimitHits > 0 {
+			rateLimit = fmt.Sprintf("%s曾被限流 %d 次%s", colorYellow, s.rateLimitHits, colorReset)
+		}
+		line("%s %s %3d/%-3d  进行中 %-2d  重试 %-3d  失败 %-3d  限流: %s",
+			fit(s.label, 12), bar(s.videos, s.total, barWidth), s.videos, s.total,
+			max(s.inFlight, 0), s.retries, s.errors, rateLimit)
+		s.mu.Unlock()
+	}
+
+	if len(d.sessions) > 0 && d.sessions[0].top != nil {
+		primary := d.sessions[0]
+		tags := primary.top(topTagCount)
+		line("")
+		line("%s实时 Top %d Tag (%s)%s", colorBold, topTagCount, primary.label, colorReset)
+		maxCount := 0
+		if len(tags) > 0 {
+			maxCount = tags[0].Count
+		}
+		for i := 0; i < topTagCount; i++ {
+			if i >= len(tags) {
+				line("")
+				continue
+			}
+			line("  %2d. %s %5d  %s", tags[i].Rank, fit(tags[i].Tag, 20), tags[i].Count, bar(tags[i].Count, maxCount, 20))
+		}
+	}
+
+	line("")
+	line("%s最近日志%s", colorBold, colorReset)
+	for i := 0; i < logLines; i++ {
+		if i < len(d.logs) {
+			line("%s%s%s", colorDim, fit(d.logs[i], 100), colorReset)
+		} else {
+			line("")
+		}
+	}
+
+	b.WriteString(clearBelow)
+	fmt.Fprint(d.out, b.String())
+}
+
+// Enabled 判断是否可以使用面板：需要用户开启且 stdout 是终端
+func Enabled(requested bool) bool {
+	return requested && IsTerminal(os.Stdout)
+}
@@ -0,0 +1,83 @@
+package dashboard
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	cursorHome  = "\x1b[H"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorPink   = "\x1b[35m"
+	colorCyan   = "\x1b[36m"
+)
+
+// IsTerminal 判断文件是否连接到终端；重定向到文件或管道时返回 false
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// displayWidth 估算字符串在终端中占用的列数，中日韩字符按两列计算
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20:
+		return 0
+	case unicode.Is(unicode.Han, r),
+		unicode.Is(unicode.Hiragana, r),
+		unicode.Is(unicode.Katakana, r),
+		unicode.Is(unicode.Hangul, r),
+		r >= 0x3000 && r <= 0x303f,
+		r >= 0xff00 && r <= 0xff60:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// fit 按显示宽度截断或补齐字符串
+func fit(s string, width int) string {
+	w := 0
+	var b strings.Builder
+	for _, r := range s {
+		rw := runeWidth(r)
+		if w+rw > width {
+			break
+		}
+		b.WriteRune(r)
+		w += rw
+	}
+	return b.String() + strings.Repeat(" ", width-w)
+}
+
+func bar(done, total, width int) string {
+	if total <= 0 {
+		return colorDim + strings.Repeat("░", width) + colorReset
+	}
+	filled := done * width / total
+	filled = min(max(filled, 0), width)
+	return colorGreen + strings.Repeat("█", filled) + colorDim + strings.Repeat("░", width-filled) + colorReset
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// utf8BOM 让 Excel 以 UTF-8 打开 CSV，避免中文乱码。
+const utf8BOM = "\xEF\xBB\xBF"
+
+func writeDelimited(basePath, ext string, comma rune, tables []Table) ([]string, error) {
+	var paths []string
+	for _, table := range tables {
+		path := basePath + "_" + table.Name + ext
+		if err := writeDelimitedFile(path, comma, table); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeDelimitedFile(path string, comma rune, table Table) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(utf8BOM); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+
+	w := csv.NewWriter(file)
+	w.Comma = comma
+	w.UseCRLF = true
+
+	if err := w.Write(table.Header); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	record := make([]string, len(table.Header))
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			record[i] = formatCell(cell)
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("写入文件失败: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return nil
+}
+
+func writeJSONL(basePath string, tables []Table) ([]string, error) {
+	var paths []string
+	for _, table := range tables {
+		path := basePath + "_" + table.Name + ".jsonl"
+		if err := writeJSONLFile(path, table); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeJSONLFile(path string, table Table) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	var line bytes.Buffer
+	for _, row := range table.Rows {
+		if err := encodeJSONRow(&line, table.Header, row); err != nil {
+			return fmt.Errorf("写入文件失败: %w", err)
+		}
+		if _, err := w.Write(line.Bytes()); err != nil {
+			return fmt.Errorf("写入文件失败: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return nil
+}
+
+// encodeJSONRow 把一行编码为 JSON 对象（含结尾换行），键按表头顺序排列，与 CSV/TSV/XLSX 的列顺序一致
+func encodeJSONRow(buf *bytes.Buffer, header []string, row []any) error {
+	buf.Reset()
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	// Encode 会在每个值后追加换行，写完后去掉
+	encode := func(v any) error {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+		return nil
+	}
+
+	buf.WriteByte('{')
+	for i, name := range header {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := encode(name); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if err := encode(row[i]); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("}\n")
+	return nil
+}
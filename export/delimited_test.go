@@ -0,0 +1,57 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testTable = Table{
+	Name:   "videos",
+	Header: []string{"round", "title", "views", "tags"},
+	Rows: [][]any{
+		{1, `标题,带"引号"`, int64(1000), "游戏|<原神>"},
+		{2, "第二行", int64(0), ""},
+	},
+}
+
+// JSONL 的键顺序与表头一致，和 CSV/TSV/XLSX 的列顺序相同
+func TestWriteJSONLKeepsHeaderOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "videos.jsonl")
+	if err := writeJSONLFile(path, testTable); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"round":1,"title":"标题,带\"引号\"","views":1000,"tags":"游戏|<原神>"}` + "\n" +
+		`{"round":2,"title":"第二行","views":0,"tags":""}` + "\n"
+	if string(data) != want {
+		t.Fatalf("JSONL 内容:\n%s\n期望:\n%s", data, want)
+	}
+}
+
+func TestWriteDelimited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "videos.csv")
+	if err := writeDelimitedFile(path, ',', testTable); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, ok := strings.CutPrefix(string(data), utf8BOM)
+	if !ok {
+		t.Fatal("CSV 应以 UTF-8 BOM 开头")
+	}
+	want := "round,title,views,tags\r\n" +
+		`1,"标题,带""引号""",1000,游戏|<原神>` + "\r\n" +
+		"2,第二行,0,\r\n"
+	if text != want {
+		t.Fatalf("CSV 内容:\n%q\n期望:\n%q", text, want)
+	}
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"biliTagAnalyse/crawler"
+	"biliTagAnalyse/parser"
+	"biliTagAnalyse/statistics"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+	FormatXLSX  = "xlsx"
+	FormatJSONL = "jsonl"
+)
+
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]any
+}
+
+func BuildTables(stats *statistics.StatsResult, videos []*crawler.VideoInfo) []Table {
+	tables := []Table{tagTable(stats)}
+	if len(videos) > 0 {
+		tables = append(tables, videoTable(videos))
+	}
+	if len(stats.CoOccurrence) > 0 {
+		tables = append(tables, pairTable(stats))
+	}
+	return tables
+}
+
+func tagTable(stats *statistics.StatsResult) Table {
+	t := Table{
+		Name:   "tags",
+		Header: []string{"rank", "dense_rank", "tag", "count", "doc_freq", "share"},
+	}
+	for _, stat := range stats.TagStats {
+		share := 0.0
+		if stats.TotalVideos > 0 {
+			share = float64(stat.Count) / float64(stats.TotalVideos)
+		}
+		t.Rows = append(t.Rows, []any{stat.Rank, stat.DenseRank, stat.Tag, stat.Count, stat.DocFreq, share})
+	}
+	return t
+}
+
+func videoTable(videos []*crawler.VideoInfo) Table {
+	t := Table{
+		Name: "videos",
+		Header: []string{
+			"round", "bvid", "link", "title", "author", "author_mid",
+			"tid", "tname", "main_tid", "main_name",
+			"views", "likes", "coins", "favorites", "shares", "replies", "danmaku", "tags",
+		},
+	}
+
+	p := parser.NewVideoParser()
+	for _, v := range videos {
+		t.Rows = append(t.Rows, []any{
+			v.Round, p.ExtractBVNumber(v.Link), v.Link, v.Title, v.Author, v.AuthorMid,
+			v.TID, v.TName, v.MainTID, v.MainName,
+			v.Views, v.Likes, v.Coins, v.Favorites, v.Shares, v.Replies, v.Danmaku,
+			strings.Join(v.Tags, "|"),
+		})
+	}
+	return t
+}
+
+func pairTable(stats *statistics.StatsResult) Table {
+	t := Table{
+		Name:   "co_occurrence",
+		Header: []string{"tag_a", "tag_b", "count"},
+	}
+	for _, pair := range stats.CoOccurrence {
+		t.Rows = append(t.Rows, []any{pair.A, pair.B, pair.Count})
+	}
+	return t
+}
+
+func formatCell(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// Export 将统计结果写成表格文件，basePath 为不带扩展名的输出路径。
+// csv/tsv/jsonl 每张表一个文件（basePath_表名.扩展名），xlsx 将所有表写入同一工作簿的不同工作表，
+// parquet 写出带类型的 Tag 数据集与 视频×Tag 长表。
+func Export(format, basePath string, stats *statistics.StatsResult, videos []*crawler.VideoInfo) ([]string, error) {
+	if err := os.MkdirAll(filepath.Dir(basePath), 0o755); err != nil {
+		return nil, fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	if format == FormatParquet {
+		return writeParquet(basePath, stats, videos)
+	}
+
+	tables := BuildTables(stats, videos)
+
+	switch format {
+	case FormatCSV:
+		return writeDelimited(basePath, ".csv", ',', tables)
+	case FormatTSV:
+		return writeDelimited(basePath, ".tsv", '\t', tables)
+	case FormatJSONL:
+		return writeJSONL(basePath, tables)
+	case FormatXLSX:
+		path := basePath + ".xlsx"
+		if err := writeXLSX(path, tables); err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+}
@@ -0,0 +1,287 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"biliTagAnalyse/crawler"
+	"biliTagAnalyse/parquet"
+	"biliTagAnalyse/parser"
+	"biliTagAnalyse/statistics"
+)
+
+const FormatParquet = "parquet"
+
+// ParquetSchemaVersion 在列发生不兼容变化时递增；读取时按列名取值，缺失的列使用零值，
+// 因此旧版本文件始终可读，只拒绝比当前程序更新的版本。
+const ParquetSchemaVersion = 1
+
+const (
+	metaSchemaVersion = "biliTagAnalyse.schema_version"
+	metaDataset       = "biliTagAnalyse.dataset"
+)
+
+var videoColumns = []parquet.Column{
+	{Name: "run_id", Type: parquet.String},
+	{Name: "label", Type: parquet.String},
+	{Name: "crawl_time", Type: parquet.String},
+	{Name: "round", Type: parquet.Int32},
+	{Name: "index", Type: parquet.Int32},
+	{Name: "bvid", Type: parquet.String},
+	{Name: "link", Type: parquet.String},
+	{Name: "title", Type: parquet.String},
+	{Name: "author", Type: parquet.String},
+	{Name: "author_mid", Type: parquet.Int64},
+	{Name: "tag", Type: parquet.String},
+	{Name: "tag_id", Type: parquet.Int64},
+	{Name: "tag_index", Type: parquet.Int32},
+	{Name: "tid", Type: parquet.Int32},
+	{Name: "tname", Type: parquet.String},
+	{Name: "main_tid", Type: parquet.Int32},
+	{Name: "main_name", Type: parquet.String},
+	{Name: "views", Type: parquet.Int64},
+	{Name: "likes", Type: parquet.Int64},
+	{Name: "coins", Type: parquet.Int64},
+	{Name: "favorites", Type: parquet.Int64},
+	{Name: "shares", Type: parquet.Int64},
+	{Name: "replies", Type: parquet.Int64},
+	{Name: "danmaku", Type: parquet.Int64},
+}
+
+var tagColumns = []parquet.Column{
+	{Name: "run_id", Type: parquet.String},
+	{Name: "label", Type: parquet.String},
+	{Name: "crawl_time", Type: parquet.String},
+	{Name: "total_videos", Type: parquet.Int64},
+	{Name: "rank", Type: parquet.Int32},
+	{Name: "dense_rank", Type: parquet.Int32},
+	{Name: "tag", Type: parquet.String},
+	{Name: "count", Type: parquet.Int64},
+	{Name: "doc_freq", Type: parquet.Int64},
+	{Name: "share", Type: parquet.Double},
+}
+
+func parquetMetadata(dataset string) map[string]string {
+	return map[string]string{
+		metaSchemaVersion: strconv.Itoa(ParquetSchemaVersion),
+		metaDataset:       dataset,
+	}
+}
+
+// writeParquet 写出 Tag 数据集和视频数据集（长表：每个 视频×Tag 一行，无 Tag 的视频保留一行空 Tag）
+func writeParquet(basePath string, stats *statistics.StatsResult, videos []*crawler.VideoInfo) ([]string, error) {
+	tags := &parquet.Table{Columns: tagColumns, Metadata: parquetMetadata("tags")}
+	for _, stat := range stats.TagStats {
+		share := 0.0
+		if stats.TotalVideos > 0 {
+			share = float64(stat.Count) / float64(stats.TotalVideos)
+		}
+		tags.Rows = append(tags.Rows, []any{
+			stats.RunID, stats.Label, stats.CrawlTime, int64(stats.TotalVideos),
+			int32(stat.Rank), int32(stat.DenseRank), stat.Tag,
+			int64(stat.Count), int64(stat.DocFreq), share,
+		})
+	}
+
+	tagPath := basePath + "_tags.parquet"
+	if err := parquet.WriteFile(tagPath, tags); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %w", tagPath, err)
+	}
+	paths := []string{tagPath}
+
+	if len(videos) == 0 {
+		return paths, nil
+	}
+
+	rows := &parquet.Table{Columns: videoColumns, Metadata: parquetMetadata("videos")}
+	p := parser.NewVideoParser()
+	for _, v := range videos {
+		crawlTime := v.CrawledAt
+		if crawlTime == "" {
+			crawlTime = stats.CrawlTime
+		}
+
+		row := func(tag string, index int) []any {
+			return []any{
+				stats.RunID, stats.Label, crawlTime,
+				int32(v.Round), int32(v.Index), p.ExtractBVNumber(v.Link), v.Link, v.Title, v.Author, v.AuthorMid,
+				tag, v.TagIDs[tag], int32(index),
+				int32(v.TID), v.TName, int32(v.MainTID), v.MainName,
+				v.Views, v.Likes, v.Coins, v.Favorites, v.Shares, v.Replies, v.Danmaku,
+			}
+		}
+
+		if len(v.Tags) == 0 {
+			rows.Rows = append(rows.Rows, row("", -1))
+			continue
+		}
+		for i, tag := range v.Tags {
+			rows.Rows = append(rows.Rows, row(tag, i))
+		}
+	}
+
+	videoPath := basePath + "_videos.parquet"
+	if err := parquet.WriteFile(videoPath, rows); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %w", videoPath, err)
+	}
+	return append(paths, videoPath), nil
+}
+
+// ReadParquet 读回 -format parquet 导出的文件。视频数据集会按 filter（即 config.json 中的 tag_filter）
+// 重新聚合出完整统计并返回视频明细，Tag 数据集只能还原 Tag 排名。
+func ReadParquet(path string, filter *statistics.TagFilter) (*statistics.StatsResult, []*crawler.VideoInfo, error) {
+	table, err := parquet.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	version, _ := strconv.Atoi(table.Metadata[metaSchemaVersion])
+	if version > ParquetSchemaVersion {
+		return nil, nil, fmt.Errorf("Parquet schema 版本 %d 高于当前支持的版本 %d", version, ParquetSchemaVersion)
+	}
+
+	r := columnReader{table: table}
+	switch table.Metadata[metaDataset] {
+	case "tags":
+		return readTagDataset(r), nil, nil
+	case "videos":
+		stats, videos := readVideoDataset(r, filter)
+		return stats, videos, nil
+	default:
+		return nil, nil, fmt.Errorf("无法识别的 Parquet 数据集: %q", table.Metadata[metaDataset])
+	}
+}
+
+func readTagDataset(r columnReader) *statistics.StatsResult {
+	stats := &statistics.StatsResult{}
+	for i := range r.table.Rows {
+		if i == 0 {
+			stats.RunID = r.str(i, "run_id")
+			stats.Label = r.str(i, "label")
+			stats.CrawlTime = r.str(i, "crawl_time")
+			stats.TotalVideos = int(r.int(i, "total_videos"))
+		}
+		stats.TagStats = append(stats.TagStats, statistics.TagStat{
+			Tag:       r.str(i, "tag"),
+			Count:     int(r.int(i, "count")),
+			Rank:      int(r.int(i, "rank")),
+			DenseRank: int(r.int(i, "dense_rank")),
+			DocFreq:   int(r.int(i, "doc_freq")),
+		})
+	}
+	stats.TotalTags = len(stats.TagStats)
+	return stats
+}
+
+// readVideoDataset 按行顺序还原视频：同一视频的行连续写出且 tag_index 从 0 递增（无 Tag 的视频只有一行，
+// tag_index 为 -1），因此 tag_index 不再递增或 round/link 变化时即为下一个视频。
+// 不能按 bvid 归并，解析不出 BV 号的视频 bvid 为空，同一轮中会被合成一个。
+// 文件中保存的是过滤前的 Tag，重新聚合时应用与爬取时相同的过滤规则，排名才与原始运行一致。
+func readVideoDataset(r columnReader, filter *statistics.TagFilter) (*statistics.StatsResult, []*crawler.VideoInfo) {
+	type taggedName struct {
+		index int
+		name  string
+	}
+
+	var videos []*crawler.VideoInfo
+	var video *crawler.VideoInfo
+	tagOrder := make(map[*crawler.VideoInfo][]taggedName)
+	lastTagIndex := -1
+
+	for i := range r.table.Rows {
+		tagIndex := int(r.int(i, "tag_index"))
+		round := int(r.int(i, "round"))
+		link := r.str(i, "link")
+		if video == nil || tagIndex <= lastTagIndex || lastTagIndex < 0 || round != video.Round || link != video.Link {
+			video = &crawler.VideoInfo{
+				Round:     round,
+				Index:     int(r.int(i, "index")),
+				Link:      link,
+				Title:     r.str(i, "title"),
+				Author:    r.str(i, "author"),
+				AuthorMid: r.int(i, "author_mid"),
+				TagIDs:    make(map[string]int64),
+				TID:       int(r.int(i, "tid")),
+				TName:     r.str(i, "tname"),
+				MainTID:   int(r.int(i, "main_tid")),
+				MainName:  r.str(i, "main_name"),
+				Views:     r.int(i, "views"),
+				Likes:     r.int(i, "likes"),
+				Coins:     r.int(i, "coins"),
+				Favorites: r.int(i, "favorites"),
+				Shares:    r.int(i, "shares"),
+				Replies:   r.int(i, "replies"),
+				Danmaku:   r.int(i, "danmaku"),
+				CrawledAt: r.str(i, "crawl_time"),
+			}
+			videos = append(videos, video)
+		}
+		lastTagIndex = tagIndex
+
+		tag := r.str(i, "tag")
+		if tag == "" {
+			continue
+		}
+		tagOrder[video] = append(tagOrder[video], taggedName{tagIndex, tag})
+		if id := r.int(i, "tag_id"); id != 0 {
+			video.TagIDs[tag] = id
+		}
+	}
+
+	agg := statistics.NewAggregator(filter)
+	for _, video := range videos {
+		tags := tagOrder[video]
+		sort.SliceStable(tags, func(a, b int) bool { return tags[a].index < tags[b].index })
+		for _, tag := range tags {
+			video.Tags = append(video.Tags, tag.name)
+		}
+		agg.Add(video, video.Round)
+	}
+
+	stats := agg.Result()
+	if len(r.table.Rows) > 0 {
+		stats.RunID = r.str(0, "run_id")
+		stats.Label = r.str(0, "label")
+	}
+	return stats, videos
+}
+
+// columnReader 按列名取值，兼容缺少某些列的旧版本文件
+type columnReader struct {
+	table *parquet.Table
+	index map[string]int
+}
+
+func (r *columnReader) col(name string) int {
+	if r.index == nil {
+		r.index = make(map[string]int, len(r.table.Columns))
+		for i, col := range r.table.Columns {
+			r.index[col.Name] = i
+		}
+	}
+	if i, ok := r.index[name]; ok {
+		return i
+	}
+	return -1
+}
+
+func (r *columnReader) str(row int, name string) string {
+	if c := r.col(name); c >= 0 {
+		v, _ := r.table.Rows[row][c].(string)
+		return v
+	}
+	return ""
+}
+
+func (r *columnReader) int(row int, name string) int64 {
+	if c := r.col(name); c >= 0 {
+		switch v := r.table.Rows[row][c].(type) {
+		case int32:
+			return int64(v)
+		case int64:
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"biliTagAnalyse/crawler"
+	"biliTagAnalyse/statistics"
+)
+
+func TestParquetVideoRoundTrip(t *testing.T) {
+	videos := []*crawler.VideoInfo{
+		{
+			Round: 1, Index: 0, Link: "https://www.bilibili.com/video/BV1xx411c7mD",
+			Title: "视频一", Author: "UP主", AuthorMid: 42,
+			Tags: []string{"游戏", "原神"}, TagIDs: map[string]int64{"游戏": 17},
+			TID: 171, TName: "电子竞技", MainTID: 4, MainName: "游戏",
+			Views: 1000, Likes: 50, Coins: 5, Favorites: 8, Shares: 1, Replies: 2, Danmaku: 3,
+			CrawledAt: "2026-10-01T12:00:00+08:00",
+		},
+		// 两个解析不出 BV 号的视频，bvid 都为空，读回时不能被合并
+		{Round: 1, Index: 1, Link: "https://www.bilibili.com/festival/a", Tags: []string{"音乐"}, TagIDs: map[string]int64{}, CrawledAt: "2026-10-01T12:00:01+08:00"},
+		{Round: 1, Index: 2, Link: "https://www.bilibili.com/festival/b", Tags: []string{"音乐", "翻唱"}, TagIDs: map[string]int64{}, CrawledAt: "2026-10-01T12:00:02+08:00"},
+		{Round: 1, Index: 3, Link: "https://www.bilibili.com/video/BV1no411tags", TagIDs: map[string]int64{}, CrawledAt: "2026-10-01T12:00:03+08:00"},
+		// 同一视频在第 2 轮再次出现
+		{Round: 2, Index: 0, Link: "https://www.bilibili.com/video/BV1xx411c7mD", Tags: []string{"游戏"}, TagIDs: map[string]int64{}, CrawledAt: "2026-10-01T12:10:00+08:00"},
+	}
+
+	agg := statistics.NewAggregator(nil)
+	for _, video := range videos {
+		agg.Add(video, video.Round)
+	}
+	want := agg.Result()
+
+	base := filepath.Join(t.TempDir(), "run")
+	paths, err := writeParquet(base, want, videos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("应写出 Tag 和视频两个数据集，实际 %v", paths)
+	}
+
+	stats, got, err := ReadParquet(base+"_videos.parquet", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, videos) {
+		t.Fatalf("读回的视频与写入的不一致:\n got: %+v\nwant: %+v", got, videos)
+	}
+	if stats.TotalVideos != want.TotalVideos || !reflect.DeepEqual(stats.TagStats, want.TagStats) {
+		t.Fatalf("读回后重新聚合的统计不一致:\n got: %d %+v\nwant: %d %+v", stats.TotalVideos, stats.TagStats, want.TotalVideos, want.TagStats)
+	}
+
+	tagStats, _, err := ReadParquet(base+"_tags.parquet", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tag := range tagStats.TagStats {
+		w := want.TagStats[i]
+		if tag.Tag != w.Tag || tag.Count != w.Count || tag.Rank != w.Rank || tag.DenseRank != w.DenseRank || tag.DocFreq != w.DocFreq {
+			t.Errorf("Tag 数据集第 %d 行 = %+v，期望 %+v", i, tag, w)
+		}
+	}
+}
+
+func TestParquetReadAppliesTagFilter(t *testing.T) {
+	filter, err := statistics.NewTagFilter(statistics.TagFilterConfig{DisableDefaultStopList: true, StopExact: []string{"活动"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	videos := []*crawler.VideoInfo{
+		{Round: 1, Index: 0, Link: "https://www.bilibili.com/video/BV1aa411c7mD", Tags: []string{"活动", "游戏"}, TagIDs: map[string]int64{}},
+		{Round: 1, Index: 1, Link: "https://www.bilibili.com/video/BV1bb411c7mD", Tags: []string{"活动", "音乐"}, TagIDs: map[string]int64{}},
+		{Round: 1, Index: 2, Link: "https://www.bilibili.com/video/BV1cc411c7mD", Tags: []string{"游戏"}, TagIDs: map[string]int64{}},
+	}
+
+	agg := statistics.NewAggregator(filter)
+	for _, video := range videos {
+		agg.Add(video, video.Round)
+	}
+	want := agg.Result()
+
+	base := filepath.Join(t.TempDir(), "run")
+	if _, err := writeParquet(base, want, videos); err != nil {
+		t.Fatal(err)
+	}
+	stats, _, err := ReadParquet(base+"_videos.parquet", filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stats.TagStats, want.TagStats) {
+		t.Fatalf("读回时应使用相同的过滤规则:\n got: %+v\nwant: %+v", stats.TagStats, want.TagStats)
+	}
+	for _, tag := range stats.TagStats {
+		if tag.Tag == "活动" {
+			t.Fatalf("被过滤的 Tag 不应出现在读回的排名中: %+v", stats.TagStats)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+type sheetDoc struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readZipPart(t *testing.T, r *zip.ReadCloser, name string) []byte {
+	t.Helper()
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	t.Fatalf("xlsx 中缺少 %s", name)
+	return nil
+}
+
+func TestWriteXLSX(t *testing.T) {
+	tables := []Table{
+		{
+			Name:   "tags",
+			Header: []string{"rank", "tag", "count", "share"},
+			Rows: [][]any{
+				{1, "游戏", int64(50), 0.5},
+				{2, "<A&B>\x01\x1f换行\n", int64(3), 0.03},
+			},
+		},
+		{Name: "co_occurrence", Header: []string{"tag_a", "tag_b", "count"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "stats.xlsx")
+	if err := writeXLSX(path, tables); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		if err := xml.Unmarshal(readZipPart(t, r, name), new(struct{})); err != nil {
+			t.Errorf("%s 不是合法 XML: %v", name, err)
+		}
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(readZipPart(t, r, "xl/workbook.xml"), &workbook); err != nil {
+		t.Fatal(err)
+	}
+	if len(workbook.Sheets) != 2 || workbook.Sheets[0].Name != "tags" || workbook.Sheets[1].Name != "co_occurrence" {
+		t.Fatalf("工作表 = %+v", workbook.Sheets)
+	}
+
+	var sheet sheetDoc
+	if err := xml.Unmarshal(readZipPart(t, r, "xl/worksheets/sheet1.xml"), &sheet); err != nil {
+		t.Fatalf("sheet1.xml 不是合法 XML: %v", err)
+	}
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("应有表头加 2 行数据，实际 %d 行", len(sheet.Rows))
+	}
+
+	header := sheet.Rows[0].Cells
+	if header[0].Ref != "A1" || header[0].Type != "inlineStr" || header[0].Inline != "rank" {
+		t.Errorf("表头单元格 = %+v", header[0])
+	}
+
+	row := sheet.Rows[2].Cells
+	if row[0].Ref != "A3" || row[0].Type != "" || row[0].Value != "2" {
+		t.Errorf("整数应写成数值单元格: %+v", row[0])
+	}
+	if row[1].Inline != "<A&B>换行\n" {
+		t.Errorf("字符串应转义并去掉控制字符，实际 %q", row[1].Inline)
+	}
+	if row[3].Ref != "D3" || row[3].Value != "0.03" {
+		t.Errorf("小数单元格 = %+v", row[3])
+	}
+
+	var empty sheetDoc
+	if err := xml.Unmarshal(readZipPart(t, r, "xl/worksheets/sheet2.xml"), &empty); err != nil {
+		t.Fatal(err)
+	}
+	if len(empty.Rows) != 1 {
+		t.Errorf("没有数据的表只应有表头，实际 %d 行", len(empty.Rows))
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %s，期望 %s", index, got, want)
+		}
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"biliTagAnalyse/analyzer"
 	"biliTagAnalyse/cmd"
 	"biliTagAnalyse/config"
 	"biliTagAnalyse/crawler"
+	"biliTagAnalyse/dashboard"
+	"biliTagAnalyse/export"
+	"biliTagAnalyse/report"
 	"biliTagAnalyse/statistics"
+	"biliTagAnalyse/utils"
 )
 
 func main() {
@@ -21,6 +27,14 @@ func main() {
 	}
 
 	fmt.Println("=== B站推荐视频 Tag 分析爬虫 ===")
+
+	if opts.Command != "" {
+		if err := runCommand(opts); err != nil {
+			log.Fatalf("执行命令 %s 失败: %v", opts.Command, err)
+		}
+		return
+	}
+
 	log.Printf("配置文件: %s", config.ResolveConfigPath(opts.ConfigPath))
 	log.Printf("运行模式: %s", opts.ModeDescription())
 
@@ -28,6 +42,36 @@ func main() {
 	if err != nil {
 		log.Fatalf("加载配置失败: %v", err)
 	}
+	if opts.Anonymous {
+		cfg.Anonymous = true
+	}
+	if opts.Baseline {
+		cfg.CrawlBaseline = true
+	}
+	if opts.TUI {
+		cfg.TUI = true
+	}
+	if cfg.Stream {
+		opts.Stream = true
+	}
+	if cfg.MapReduce {
+		opts.MapReduce = true
+	}
+	if cfg.WithTitles {
+		opts.WithTitles = true
+	}
+	if cfg.Categorize {
+		opts.Categorize = true
+	}
+	if cfg.Cluster {
+		opts.Cluster = true
+	}
+	if opts.ContextWindow == 0 {
+		opts.ContextWindow = cfg.ContextWindow
+	}
+	if opts.Prompt == "" {
+		opts.Prompt = cfg.Prompt
+	}
 
 	if opts.OllamaURL == "" || opts.OllamaURL == cmd.DefaultOllamaURL {
 		opts.OllamaURL = cfg.OllamaURL
@@ -41,6 +85,16 @@ func main() {
 	if opts.APIKey == "" {
 		opts.APIKey = cfg.APIKey
 	}
+	if opts.APIModel == "" {
+		opts.APIModel = cfg.APIModel
+	}
+	if opts.APIFormat == "" {
+		opts.APIFormat = strings.ToLower(cfg.APIFormat)
+	}
+	opts.Generation.Fill(cfg.GenerationParams)
+	if err := opts.Generation.Validate(); err != nil {
+		log.Fatalf("配置验证失败: %v", err)
+	}
 
 	log.Printf("配置信息:")
 	log.Printf("  - 爬取次数: %d", cfg.CrawlCount)
@@ -50,23 +104,62 @@ func main() {
 	log.Printf("  - 重试次数: %d", cfg.RetryCount)
 	log.Printf("  - 输出文件: %s", cfg.OutputFile)
 
+	// 在爬取前创建模型后端，配置错误时尽早退出
+	az, err := analyzer.NewAnalyzer(opts, cfg.Providers)
+	if err != nil {
+		log.Fatalf("初始化模型后端失败: %v", err)
+	}
+	az.SetTaxonomy(cfg.Taxonomy)
+	az.SetClustering(cfg.Clustering)
+	az.SetResponseCache(cfg.ResponseCache)
+
 	var statsResult *statistics.StatsResult
+	var inputVideos []*crawler.VideoInfo
+	crawled := false
+
+	tagFilter, err := statistics.NewTagFilter(cfg.TagFilter)
+	if err != nil {
+		log.Fatalf("加载Tag过滤规则失败: %v", err)
+	}
 
-	if opts.InputFile != "" && (opts.RunMode == cmd.ModeOllama || opts.RunMode == cmd.ModeAPI) {
+	if opts.InputFile != "" && opts.RunMode != cmd.ModeJSONOnly {
 		log.Printf("从文件加载数据: %s", opts.InputFile)
-		statsResult, err = analyzer.LoadStatsFromFile(opts.InputFile)
+		if strings.EqualFold(filepath.Ext(opts.InputFile), ".parquet") {
+			statsResult, inputVideos, err = export.ReadParquet(opts.InputFile, tagFilter)
+		} else {
+			statsResult, err = analyzer.LoadStatsFromFile(opts.InputFile)
+		}
 		if err != nil {
 			log.Fatalf("加载输入文件失败: %v", err)
 		}
 	} else {
-		statsResult, err = runCrawler(cfg)
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("配置验证失败: %v", err)
+		}
+		statsResult, err = runCrawler(cfg, tagFilter)
 		if err != nil {
 			log.Fatalf("爬取失败: %v", err)
 		}
+		crawled = true
+	}
+
+	// 原始视频数据用于导出视频明细、分块分析的标题和提示词模板中的 .Videos
+	var videos []*crawler.VideoInfo
+	if (opts.Format != "" && opts.Format != cmd.FormatJSON) || opts.RunMode != cmd.ModeJSONOnly {
+		videos = inputVideos
+		if crawled && cfg.RawOutputFile != "" {
+			rounds, err := crawler.LoadVideos(cfg.RawOutputFile)
+			if err != nil {
+				log.Printf("读取原始数据失败，跳过视频明细: %v", err)
+			}
+			for _, roundVideos := range rounds {
+				videos = append(videos, roundVideos...)
+			}
+		}
+		az.SetVideos(videos)
 	}
 
 	log.Println("\n=== 执行分析 ===")
-	az := analyzer.NewAnalyzer(opts)
 	analysisResult, err := az.Analyze(statsResult)
 	if err != nil {
 		log.Fatalf("分析失败: %v", err)
@@ -82,70 +175,313 @@ func main() {
 	}
 
 	fmt.Printf("\n结果已保存到: %s\n", outputPath)
+
+	if err := report.WriteMarkdown(analysisResult, cfg.ReportFile, cfg.ReportTemplate, report.DefaultTopN); err != nil {
+		log.Printf("生成Markdown报告失败: %v", err)
+	} else {
+		fmt.Printf("Markdown报告: %s\n", cfg.ReportFile)
+	}
+
+	if opts.Format != "" && opts.Format != cmd.FormatJSON {
+		basePath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
+		paths, err := export.Export(opts.Format, basePath, analysisResult.RawStats, videos)
+		if err != nil {
+			log.Fatalf("导出 %s 失败: %v", opts.Format, err)
+		}
+		for _, path := range paths {
+			fmt.Printf("已导出: %s\n", path)
+		}
+	}
 	printAnalysisSummary(analysisResult, opts.RunMode)
 	log.Println("=== 程序运行完成 ===")
 }
 
-func runCrawler(cfg *config.Config) (*statistics.StatsResult, error) {
-	homepageCrawler := crawler.NewHomepageCrawler(
-		cfg.Cookie,
-		cfg.RetryCount,
-		cfg.RetryDelay,
-		cfg.RequestInterval,
-		cfg.MaxConcurrent,
-	)
-
-	videoCrawler := crawler.NewVideoCrawler(
-		cfg.Cookie,
-		cfg.RetryCount,
-		cfg.RetryDelay,
-		cfg.RequestInterval,
-		cfg.MaxConcurrent,
-	)
-
-	var allVideos [][]*crawler.VideoInfo
+type crawlSession struct {
+	label     string
+	rawOutput string
+	snapshot  string
+	homepage  *crawler.HomepageCrawler
+	video     *crawler.VideoCrawler
+	agg       *statistics.Aggregator
+	raw       *crawler.VideoWriter
+	progress  *dashboard.Session
+}
+
+func newCrawlSession(cfg *config.Config, label, cookie, rawOutput, snapshot string, tagFilter *statistics.TagFilter) *crawlSession {
+	agg := statistics.NewAggregator(tagFilter)
+	agg.SetLabel(label)
+	if snapshot != "" {
+		agg.RetainRecords()
+	}
 
-	for i := 0; i < cfg.CrawlCount; i++ {
-		log.Printf("\n--- 第 %d/%d 轮爬取 ---", i+1, cfg.CrawlCount)
+	return &crawlSession{
+		label:     label,
+		rawOutput: rawOutput,
+		snapshot:  snapshot,
+		homepage: crawler.NewHomepageCrawler(
+			cookie,
+			cfg.RetryCount,
+			cfg.RetryDelay,
+			cfg.RequestInterval,
+			cfg.MaxConcurrent,
+		),
+		video: crawler.NewVideoCrawler(
+			cookie,
+			cfg.RetryCount,
+			cfg.RetryDelay,
+			cfg.RequestInterval,
+			cfg.MaxConcurrent,
+		),
+		agg: agg,
+	}
+}
+
+func buildCrawlSessions(cfg *config.Config, tagFilter *statistics.TagFilter) []*crawlSession {
+	if cfg.Anonymous {
+		if cfg.CrawlBaseline {
+			log.Println("匿名模式下忽略未登录基线爬取")
+		}
+		return []*crawlSession{
+			newCrawlSession(cfg, anonymousLabel(cfg), utils.AnonymousCookie(), cfg.RawOutputFile, cfg.SnapshotFile, tagFilter),
+		}
+	}
+
+	label := cfg.AccountLabel
+	if label == "" {
+		label = "logged_in"
+	}
+	sessions := []*crawlSession{newCrawlSession(cfg, label, cfg.Cookie, cfg.RawOutputFile, cfg.SnapshotFile, tagFilter)}
 
-		links, err := homepageCrawler.CrawlHomepage()
+	if cfg.CrawlBaseline {
+		// 基线的原始数据和快照与登录会话分开保存，两者都可以单独续跑或合并
+		rawOutput := withSuffix(cfg.RawOutputFile, "_anonymous")
+		snapshot := withSuffix(cfg.SnapshotFile, "_anonymous")
+		sessions = append(sessions, newCrawlSession(cfg, "anonymous", utils.AnonymousCookie(), rawOutput, snapshot, tagFilter))
+	}
+
+	return sessions
+}
+
+// withSuffix 在扩展名前插入后缀，空路径保持为空
+func withSuffix(path, suffix string) string {
+	if path == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + suffix + ext
+}
+
+func anonymousLabel(cfg *config.Config) string {
+	if cfg.AccountLabel != "" {
+		return cfg.AccountLabel
+	}
+	return "anonymous"
+}
+
+// crawlRound 爬取第 round 轮。轮次由调用方统一传入，某个会话某一轮失败时，
+// 后续各轮的轮次仍与其他会话一致，登录与基线会话可以逐轮对比。
+func (s *crawlSession) crawlRound(round int) {
+	links, err := s.homepage.CrawlHomepage()
+	if err != nil {
+		log.Printf("[%s] 爬取首页失败: %v", s.label, err)
+		return
+	}
+
+	if len(links) == 0 {
+		log.Printf("[%s] 未获取到视频链接，可能需要使用无头浏览器", s.label)
+		return
+	}
+
+	if s.raw == nil && s.rawOutput != "" {
+		s.raw, err = crawler.NewVideoWriter(s.rawOutput)
 		if err != nil {
-			log.Printf("爬取首页失败: %v", err)
-			continue
+			log.Printf("[%s] 无法保存原始数据: %v", s.label, err)
+			s.rawOutput = ""
+		}
+	}
+
+	if s.progress != nil {
+		s.progress.StartRound(len(links))
+	}
+	videos := make(chan *crawler.VideoInfo)
+	done := make(chan int)
+
+	go func() {
+		count := 0
+		for video := range videos {
+			s.agg.Add(video, video.Round)
+			if s.raw != nil {
+				if err := s.raw.Write(video); err != nil {
+					log.Printf("[%s] %v", s.label, err)
+				}
+			}
+			if s.progress != nil {
+				s.progress.VideoDone()
+			}
+			count++
 		}
+		done <- count
+	}()
 
-		if len(links) == 0 {
-			log.Println("未获取到视频链接，可能需要使用无头浏览器")
-			continue
+	s.video.StreamVideos(links, round, videos)
+	close(videos)
+	count := <-done
+
+	log.Printf("[%s] 第 %d 轮爬取完成，获取到 %d 个视频", s.label, round, count)
+}
+
+func (s *crawlSession) saveSnapshot() {
+	if s.snapshot == "" {
+		return
+	}
+	if err := statistics.SaveSnapshot(s.agg.Snapshot(), s.snapshot); err != nil {
+		log.Printf("[%s] 保存统计快照失败: %v", s.label, err)
+	}
+}
+
+func (s *crawlSession) close() {
+	if s.raw == nil {
+		return
+	}
+	if err := s.raw.Close(); err != nil {
+		log.Printf("[%s] 保存原始数据失败: %v", s.label, err)
+		return
+	}
+	log.Printf("[%s] 原始数据已保存到: %s", s.label, s.rawOutput)
+}
+
+func runCrawler(cfg *config.Config, tagFilter *statistics.TagFilter) (*statistics.StatsResult, error) {
+	sessions := buildCrawlSessions(cfg, tagFilter)
+	primary := sessions[0]
+
+	board := startDashboard(cfg, sessions)
+
+	for i := 0; i < cfg.CrawlCount; i++ {
+		log.Printf("\n--- 第 %d/%d 轮爬取 ---", i+1, cfg.CrawlCount)
+		if board != nil {
+			board.SetRound(i + 1)
 		}
 
-		videos := videoCrawler.CrawlVideosConcurrently(links)
-		allVideos = append(allVideos, videos)
+		for _, session := range sessions {
+			session.crawlRound(i + 1)
+			session.saveSnapshot()
+		}
 
-		log.Printf("第 %d 轮爬取完成，获取到 %d 个视频", i+1, len(videos))
+		if cfg.AutoStop && i+1 >= cfg.AutoStopMinRounds && i < cfg.CrawlCount-1 {
+			sampling := primary.agg.Sampling(statistics.DefaultStabilityTopK)
+			log.Printf("Top %d 排名变化概率: %.1f%%", sampling.TopK, sampling.TopChangeProbability*100)
+			if sampling.TopChangeProbability <= cfg.AutoStopThreshold {
+				log.Printf("Tag 排名已收敛（阈值 %.1f%%），提前结束爬取", cfg.AutoStopThreshold*100)
+				break
+			}
+		}
 
 		if i < cfg.CrawlCount-1 {
 			log.Printf("等待 %d 秒后进行下一轮爬取...", cfg.CrawlInterval)
-			time.Sleep(time.Duration(cfg.CrawlInterval) * time.Second)
+			wait := time.Duration(cfg.CrawlInterval) * time.Second
+			if board != nil {
+				board.WaitUntil(time.Now().Add(wait))
+			}
+			time.Sleep(wait)
 		}
 	}
 
-	if len(allVideos) == 0 {
+	if board != nil {
+		board.Stop()
+	}
+
+	for _, session := range sessions {
+		session.close()
+	}
+
+	if primary.agg.Videos() == 0 {
 		return nil, fmt.Errorf("未获取到任何视频数据，请检查网络连接或 Cookie 是否有效")
 	}
 
 	log.Println("\n=== 统计 Tag ===")
-	result := statistics.CountTagsMultipleRounds(allVideos)
+	result := primary.agg.Result()
+
+	history, err := statistics.LoadHistory(cfg.HistoryFile)
+	if err != nil {
+		log.Printf("加载历史记录失败，新颖度将不参考历史，本次运行仍会追加记录: %v", err)
+	}
+	result.Diversity = primary.agg.Diversity(history)
+	result.Sampling = primary.agg.Sampling(statistics.DefaultStabilityTopK)
+
+	if len(sessions) > 1 {
+		baselineSession := sessions[1]
+		if baselineSession.agg.Videos() == 0 {
+			log.Printf("[%s] 未获取到基线数据，跳过个性化分析", baselineSession.label)
+		} else {
+			baseline := baselineSession.agg.Result()
+			baseline.Diversity = baselineSession.agg.Diversity(history)
+			result.Baseline = baseline
+			result.Personalization = statistics.ComputePersonalization(result, baseline)
+		}
+	}
 
+	if err := history.Append(result); err != nil {
+		log.Printf("保存历史记录失败: %v", err)
+	}
+
+	logStats(result)
+	return result, nil
+}
+
+// startDashboard 在开启 tui 且 stdout 为终端时启动终端面板，否则返回 nil 并继续使用普通日志
+func startDashboard(cfg *config.Config, sessions []*crawlSession) *dashboard.Dashboard {
+	if !cfg.TUI {
+		return nil
+	}
+	if !dashboard.Enabled(cfg.TUI) {
+		log.Println("标准输出不是终端，终端面板已关闭，使用普通日志输出")
+		return nil
+	}
+
+	board := dashboard.New(os.Stdout, cfg.CrawlCount)
+	for _, session := range sessions {
+		session.progress = board.AddSession(session.label, session.agg.TopTags)
+		session.homepage.SetObserver(session.progress)
+		session.video.SetObserver(session.progress)
+	}
+	board.Start()
+	return board
+}
+
+func logStats(result *statistics.StatsResult) {
 	log.Printf("统计结果:")
 	log.Printf("  - 总视频数: %d", result.TotalVideos)
 	log.Printf("  - 总 Tag 数: %d", result.TotalTags)
+	if fr := result.FilterReport; fr != nil {
+		log.Printf("  - 过滤 Tag: %d 次 / %d 个 (占比 %.1f%%)", fr.FilteredOccurrences, fr.FilteredDistinct, fr.FilteredRatio*100)
+	}
+	if len(result.PartitionStats) > 0 {
+		log.Printf("  - 分区分布:")
+		for i := 0; i < len(result.PartitionStats) && i < 5; i++ {
+			p := result.PartitionStats[i]
+			log.Printf("    %s: %d (%.1f%%)", p.Name, p.Count, p.Share*100)
+		}
+	}
+	if cc := result.CreatorConcentration; cc != nil {
+		log.Printf("  - UP主: %d 位, Top %d 占比 %.1f%%, HHI %.3f, Gini %.3f", cc.Creators, cc.TopN, cc.TopNShare*100, cc.HHI, cc.Gini)
+	}
 	log.Printf("  - Top 10 Tags:")
 	for i := 0; i < len(result.TagStats) && i < 10; i++ {
-		log.Printf("    %d. %s (%d)", i+1, result.TagStats[i].Tag, result.TagStats[i].Count)
+		log.Printf("    %d. %s (%d)", result.TagStats[i].Rank, result.TagStats[i].Tag, result.TagStats[i].Count)
+	}
+	if byViews := result.WeightedRankings[statistics.MetricViews]; len(byViews) > 0 {
+		log.Printf("  - 播放量加权 Top 5 Tags:")
+		for i := 0; i < len(byViews) && i < 5; i++ {
+			log.Printf("    %d. %s (总播放 %.0f, 视频 %d)", i+1, byViews[i].Tag, byViews[i].Weight, byViews[i].Videos)
+		}
 	}
 
-	return result, nil
+	if sp := result.Sampling; sp != nil {
+		log.Printf("  - 抽样充分性: 已观测 %d 个Tag, Chao1 估计 %.0f 个 (未观测约 %.0f), Top %d 变化概率 %.1f%%", sp.ObservedTags, sp.Chao1, sp.UnseenEstimate, sp.TopK, sp.TopChangeProbability*100)
+	}
+	if p := result.Personalization; p != nil {
+		log.Printf("  - 个性化分数: %.3f (Tag 余弦 %.3f, Jaccard %.3f, 分区距离 %.3f)", p.Score, p.TagCosine, p.TagJaccard, p.PartitionDistance)
+	}
 }
 
 func saveResults(result *analyzer.AnalysisResult, outputPath string, mode cmd.RunMode) error {
@@ -177,13 +513,93 @@ func printAnalysisSummary(result *analyzer.AnalysisResult, mode cmd.RunMode) {
 	fmt.Printf("总视频数: %d\n", result.RawStats.TotalVideos)
 	fmt.Printf("总Tag数: %d\n", result.RawStats.TotalTags)
 
+	if fr := result.RawStats.FilterReport; fr != nil && fr.FilteredOccurrences > 0 {
+		fmt.Println("\nTag 过滤:")
+		fmt.Printf("  已过滤 %d 次出现 / %d 个不同Tag (占比 %.1f%%)\n", fr.FilteredOccurrences, fr.FilteredDistinct, fr.FilteredRatio*100)
+		for i := 0; i < len(fr.FilteredTags) && i < 5; i++ {
+			fmt.Printf("  - %s (%d)\n", fr.FilteredTags[i].Tag, fr.FilteredTags[i].Count)
+		}
+	}
+
 	fmt.Println("\nTop 5 Tags:")
 	for i := 0; i < len(result.TopTags) && i < 5; i++ {
 		fmt.Printf("  %d. %s (次数: %d)\n", i+1, result.TopTags[i].Tag, result.TopTags[i].Count)
 	}
 
+	if d := result.RawStats.Diversity; d != nil {
+		fmt.Println("\n推荐多样性:")
+		fmt.Printf("  Tag 熵: %.2f bit, Simpson: %.3f\n", d.Run.TagEntropy, d.Run.TagSimpson)
+		fmt.Printf("  分区熵: %.2f bit, Simpson: %.3f\n", d.Run.PartitionEntropy, d.Run.PartitionSimpson)
+		fmt.Printf("  新颖度: %.1f%%, 列表内相似度: %.3f\n", d.Run.Novelty*100, d.Run.IntraListSimilarity)
+		for _, r := range d.Rounds {
+			fmt.Printf("  第 %d 轮: Tag 熵 %.2f, 新颖度 %.1f%%, 相似度 %.3f\n", r.Round, r.TagEntropy, r.Novelty*100, r.IntraListSimilarity)
+		}
+		if n := len(d.History); n > 0 {
+			prev := d.History[n-1].Diversity
+			fmt.Printf("  较上次运行: Tag 熵 %+.2f, 新颖度 %+.1f%%\n", d.Run.TagEntropy-prev.TagEntropy, (d.Run.Novelty-prev.Novelty)*100)
+		}
+	}
+
+	if p := result.RawStats.Personalization; p != nil {
+		fmt.Printf("\n个性化程度 (%s vs %s):\n", p.LoggedIn, p.Anonymous)
+		fmt.Printf("  个性化分数: %.3f\n", p.Score)
+		fmt.Printf("  Tag 余弦相似度: %.3f, Jaccard: %.3f, Spearman: %.3f\n", p.TagCosine, p.TagJaccard, p.Spearman)
+		fmt.Printf("  主分区分布距离: %.3f\n", p.PartitionDistance)
+	}
+
 	if mode != cmd.ModeJSONOnly && result.Summary != "" {
-		fmt.Println("\n模型分析结果:")
+		source := ""
+		if result.FromCache {
+			source = ", 来自缓存"
+		}
+		fmt.Printf("\n模型分析结果 (%s / %s%s):\n", result.Provider, result.Model, source)
+		if result.Generation != nil {
+			fmt.Printf("生成参数: %s\n", result.Generation)
+		}
 		fmt.Println(result.Summary)
+		if len(result.Trends) > 0 {
+			fmt.Println("\n趋势:")
+			for _, trend := range result.Trends {
+				fmt.Printf("  - %s\n", trend)
+			}
+		}
+		if len(result.Suggestions) > 0 {
+			fmt.Println("\n建议:")
+			for _, suggestion := range result.Suggestions {
+				fmt.Printf("  - %s\n", suggestion)
+			}
+		}
+		if c := result.Categories; c != nil {
+			fmt.Printf("\nTag 分类 (新分类 %d 个, 缓存命中 %d 个, 未分类 %d 个):\n", c.Classified, c.CacheHits, c.Unclassified)
+			for _, category := range c.Categories {
+				fmt.Printf("  - %s: %d 个Tag, %d 次 (%.1f%%)\n", category.Category, category.Tags, category.Count, category.Share*100)
+			}
+			if c.NeedsReview > 0 {
+				fmt.Printf("  %d 个Tag置信度较低需要复核，确认后可在 %s 中修改分类并设置 \"manual\": true\n", c.NeedsReview, c.CacheFile)
+			}
+		}
+		if c := result.Clusters; c != nil {
+			fmt.Printf("\n语义聚类 (%s, 阈值 %.2f): %d 个Tag合并为 %d 组, 新请求向量 %d 个, 缓存命中 %d 个\n",
+				c.Model, c.Threshold, c.RawTags, len(c.Merged), c.Embedded, c.CacheHits)
+			fmt.Println("  原始 Top 5:")
+			for i := 0; i < len(result.RawStats.TagStats) && i < 5; i++ {
+				fmt.Printf("    %d. %s (%d)\n", i+1, result.RawStats.TagStats[i].Tag, result.RawStats.TagStats[i].Count)
+			}
+			fmt.Println("  合并后 Top 5:")
+			for i := 0; i < len(c.Merged) && i < 5; i++ {
+				fmt.Printf("    %d. %s (%d)", i+1, c.Merged[i].Label, c.Merged[i].Count)
+				if len(c.Merged[i].Members) > 1 {
+					names := make([]string, len(c.Merged[i].Members))
+					for j, m := range c.Merged[i].Members {
+						names[j] = m.Tag
+					}
+					fmt.Printf(" = %s", strings.Join(names, " + "))
+				}
+				fmt.Println()
+			}
+		}
+		for _, warning := range result.Warnings {
+			fmt.Printf("\n注意: %s\n", warning)
+		}
 	}
 }
@@ -0,0 +1,366 @@
+// Package parquet 实现一个只依赖标准库的最小 Parquet 读写器：
+// 单行组、REQUIRED 列、PLAIN 编码、不压缩，足以被 DuckDB / pandas / polars 直接读取。
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+)
+
+const magic = "PAR1"
+
+// Type 是列的物理类型
+type Type int
+
+const (
+	Int32 Type = iota
+	Int64
+	Double
+	String
+)
+
+// Parquet 物理类型编号
+var physicalTypes = map[Type]int32{
+	Int32:  1,
+	Int64:  2,
+	Double: 5,
+	String: 6,
+}
+
+const (
+	encodingPlain      int32 = 0
+	encodingRLE        int32 = 3
+	pageTypeData       int32 = 0
+	codecUncompressed  int32 = 0
+	repetitionRequired int32 = 0
+	convertedUTF8      int32 = 0
+)
+
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Table 是按行组织的数据，每行的值类型需与列类型一致：
+// Int32 -> int32，Int64 -> int64，Double -> float64，String -> string
+type Table struct {
+	Columns  []Column
+	Rows     [][]any
+	Metadata map[string]string
+}
+
+// ColumnIndex 返回列名对应的下标，不存在时返回 -1
+func (t *Table) ColumnIndex(name string) int {
+	for i, col := range t.Columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func WriteFile(path string, table *Table) error {
+	data, err := Encode(table)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func Encode(table *Table) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+
+	var chunks []any
+	var totalSize int64
+
+	for i, col := range table.Columns {
+		values, err := encodeColumn(table.Rows, i, col)
+		if err != nil {
+			return nil, err
+		}
+
+		header := encodeStruct(tStructValue{
+			{1, pageTypeData},
+			{2, int32(len(values))},
+			{3, int32(len(values))},
+			{5, tStructValue{
+				{1, int32(len(table.Rows))},
+				{2, encodingPlain},
+				{3, encodingRLE},
+				{4, encodingRLE},
+			}},
+		})
+
+		offset := int64(buf.Len())
+		buf.Write(header)
+		buf.Write(values)
+		size := int64(len(header) + len(values))
+		totalSize += size
+
+		chunks = append(chunks, tStructValue{
+			{2, offset},
+			{3, tStructValue{
+				{1, physicalTypes[col.Type]},
+				{2, tListValue{tI32, []any{encodingPlain, encodingRLE}}},
+				{3, tListValue{tBinary, []any{col.Name}}},
+				{4, codecUncompressed},
+				{5, int64(len(table.Rows))},
+				{6, size},
+				{7, size},
+				{9, offset},
+			}},
+		})
+	}
+
+	schema := []any{tStructValue{
+		{4, "schema"},
+		{5, int32(len(table.Columns))},
+	}}
+	for _, col := range table.Columns {
+		element := tStructValue{
+			{1, physicalTypes[col.Type]},
+			{3, repetitionRequired},
+			{4, col.Name},
+		}
+		if col.Type == String {
+			element = append(element, tField{6, convertedUTF8})
+		}
+		schema = append(schema, element)
+	}
+
+	meta := tStructValue{
+		{1, int32(1)},
+		{2, tListValue{tStruct, schema}},
+		{3, int64(len(table.Rows))},
+		{4, tListValue{tStruct, []any{tStructValue{
+			{1, tListValue{tStruct, chunks}},
+			{2, totalSize},
+			{3, int64(len(table.Rows))},
+		}}}},
+	}
+	if len(table.Metadata) > 0 {
+		var kvs []any
+		for _, key := range sortedKeys(table.Metadata) {
+			kvs = append(kvs, tStructValue{{1, key}, {2, table.Metadata[key]}})
+		}
+		meta = append(meta, tField{5, tListValue{tStruct, kvs}})
+	}
+	meta = append(meta, tField{6, "biliTagAnalyse"})
+
+	footer := encodeStruct(meta)
+	buf.Write(footer)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(footer)))
+	buf.WriteString(magic)
+
+	return buf.Bytes(), nil
+}
+
+func encodeColumn(rows [][]any, index int, col Column) ([]byte, error) {
+	var buf bytes.Buffer
+	var tmp [8]byte
+
+	for r, row := range rows {
+		if index >= len(row) {
+			return nil, fmt.Errorf("parquet: 第 %d 行缺少列 %s", r, col.Name)
+		}
+		value := row[index]
+
+		ok := true
+		switch col.Type {
+		case Int32:
+			var v int32
+			if v, ok = value.(int32); ok {
+				binary.LittleEndian.PutUint32(tmp[:4], uint32(v))
+				buf.Write(tmp[:4])
+			}
+		case Int64:
+			var v int64
+			if v, ok = value.(int64); ok {
+				binary.LittleEndian.PutUint64(tmp[:], uint64(v))
+				buf.Write(tmp[:])
+			}
+		case Double:
+			var v float64
+			if v, ok = value.(float64); ok {
+				binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(v))
+				buf.Write(tmp[:])
+			}
+		case String:
+			var v string
+			if v, ok = value.(string); ok {
+				binary.LittleEndian.PutUint32(tmp[:4], uint32(len(v)))
+				buf.Write(tmp[:4])
+				buf.WriteString(v)
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("parquet: 第 %d 行列 %s 的值类型 %T 不匹配", r, col.Name, value)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func ReadFile(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+func Decode(data []byte) (*Table, error) {
+	if len(data) < 12 || string(data[:4]) != magic || string(data[len(data)-4:]) != magic {
+		return nil, fmt.Errorf("parquet: 不是有效的 Parquet 文件")
+	}
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	if footerStart < 4 {
+		return nil, fmt.Errorf("parquet: 元数据长度无效")
+	}
+
+	r := &compactReader{data: data[footerStart : len(data)-8]}
+	meta, err := r.readStruct()
+	if err != nil {
+		return nil, err
+	}
+
+	table := &Table{Metadata: make(map[string]string)}
+	for _, item := range fieldList(meta, 5) {
+		kv, _ := item.(map[int16]any)
+		table.Metadata[fieldString(kv, 1)] = fieldString(kv, 2)
+	}
+
+	types := make(map[int64]Type, len(physicalTypes))
+	for t, id := range physicalTypes {
+		types[int64(id)] = t
+	}
+
+	schema := fieldList(meta, 2)
+	for i, item := range schema {
+		if i == 0 {
+			continue
+		}
+		element, _ := item.(map[int16]any)
+		if _, nested := element[5]; nested {
+			return nil, fmt.Errorf("parquet: 不支持嵌套列 %s", fieldString(element, 4))
+		}
+		if fieldInt(element, 3) != int64(repetitionRequired) {
+			return nil, fmt.Errorf("parquet: 仅支持 REQUIRED 列，%s 不是", fieldString(element, 4))
+		}
+		t, ok := types[fieldInt(element, 1)]
+		if !ok {
+			return nil, fmt.Errorf("parquet: 列 %s 的物理类型不受支持", fieldString(element, 4))
+		}
+		table.Columns = append(table.Columns, Column{Name: fieldString(element, 4), Type: t})
+	}
+
+	// 每行每列至少占 1 bit（布尔值按位打包），行数超过文件能容纳的上限说明元数据损坏，不能按它分配内存
+	maxRows := int64(len(data)) * 8
+	for _, item := range fieldList(meta, 4) {
+		group, _ := item.(map[int16]any)
+		count := fieldInt(group, 3)
+		if count < 0 || count > maxRows-int64(len(table.Rows)) {
+			return nil, fmt.Errorf("parquet: 行组的行数无效: %d", count)
+		}
+		numRows := int(count)
+		rows := make([][]any, numRows)
+		for i := range rows {
+			rows[i] = make([]any, len(table.Columns))
+		}
+
+		chunks := fieldList(group, 1)
+		if len(chunks) != len(table.Columns) {
+			return nil, fmt.Errorf("parquet: 行组列数与 schema 不一致")
+		}
+		for c, chunkItem := range chunks {
+			chunk, _ := chunkItem.(map[int16]any)
+			colMeta := fieldStruct(chunk, 3)
+			if fieldInt(colMeta, 4) != int64(codecUncompressed) {
+				return nil, fmt.Errorf("parquet: 列 %s 使用了压缩，暂不支持", table.Columns[c].Name)
+			}
+			if err := decodeChunk(data, int(fieldInt(colMeta, 9)), numRows, table.Columns[c], rows, c); err != nil {
+				return nil, err
+			}
+		}
+		table.Rows = append(table.Rows, rows...)
+	}
+
+	return table, nil
+}
+
+func decodeChunk(data []byte, offset, numRows int, col Column, rows [][]any, index int) error {
+	read := 0
+	for read < numRows {
+		if offset <= 0 || offset >= len(data) {
+			return fmt.Errorf("parquet: 列 %s 的页偏移无效", col.Name)
+		}
+		r := &compactReader{data: data, pos: offset}
+		header, err := r.readStruct()
+		if err != nil {
+			return err
+		}
+		if fieldInt(header, 1) != int64(pageTypeData) {
+			return fmt.Errorf("parquet: 列 %s 含有不支持的页类型", col.Name)
+		}
+		pageHeader := fieldStruct(header, 5)
+		if fieldInt(pageHeader, 2) != int64(encodingPlain) {
+			return fmt.Errorf("parquet: 列 %s 不是 PLAIN 编码", col.Name)
+		}
+
+		size := int(fieldInt(header, 3))
+		if r.pos+size > len(data) {
+			return fmt.Errorf("parquet: 列 %s 的页数据越界", col.Name)
+		}
+		page := data[r.pos : r.pos+size]
+		count := int(fieldInt(pageHeader, 1))
+		if read+count > numRows {
+			return fmt.Errorf("parquet: 列 %s 的值数量超过行数", col.Name)
+		}
+
+		pos := 0
+		for i := 0; i < count; i++ {
+			var value any
+			switch col.Type {
+			case Int32:
+				if pos+4 > len(page) {
+					return fmt.Errorf("parquet: 列 %s 数据不完整", col.Name)
+				}
+				value = int32(binary.LittleEndian.Uint32(page[pos:]))
+				pos += 4
+			case Int64, Double:
+				if pos+8 > len(page) {
+					return fmt.Errorf("parquet: 列 %s 数据不完整", col.Name)
+				}
+				bits := binary.LittleEndian.Uint64(page[pos:])
+				if col.Type == Int64 {
+					value = int64(bits)
+				} else {
+					value = math.Float64frombits(bits)
+				}
+				pos += 8
+			case String:
+				if pos+4 > len(page) {
+					return fmt.Errorf("parquet: 列 %s 数据不完整", col.Name)
+				}
+				n := int(binary.LittleEndian.Uint32(page[pos:]))
+				pos += 4
+				if pos+n > len(page) {
+					return fmt.Errorf("parquet: 列 %s 数据不完整", col.Name)
+				}
+				value = string(page[pos : pos+n])
+				pos += n
+			}
+			rows[read+i][index] = value
+		}
+
+		read += count
+		offset = r.pos + size
+	}
+	return nil
+}
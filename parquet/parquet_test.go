@@ -0,0 +1,101 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	table := &Table{
+		Columns: []Column{
+			{Name: "i32", Type: Int32},
+			{Name: "i64", Type: Int64},
+			{Name: "f64", Type: Double},
+			{Name: "str", Type: String},
+		},
+		Rows: [][]any{
+			{int32(1), int64(1) << 40, 0.5, "游戏"},
+			{int32(-1), int64(-7), math.Inf(1), ""},
+			{int32(math.MaxInt32), int64(math.MinInt64), -0.25, strings.Repeat("长字符串", 100)},
+		},
+		Metadata: map[string]string{"b": "2", "a": "1"},
+	}
+
+	path := filepath.Join(t.TempDir(), "table.parquet")
+	if err := WriteFile(path, table); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, table) {
+		t.Fatalf("读回的表与写入的不一致:\n got: %+v\nwant: %+v", got, table)
+	}
+}
+
+func TestRoundTripEmpty(t *testing.T) {
+	table := &Table{Columns: []Column{{Name: "tag", Type: String}}}
+	data, err := Encode(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Rows) != 0 || !reflect.DeepEqual(got.Columns, table.Columns) {
+		t.Fatalf("空表读回不一致: %+v", got)
+	}
+}
+
+func TestEncodeRejectsMismatchedType(t *testing.T) {
+	table := &Table{
+		Columns: []Column{{Name: "count", Type: Int64}},
+		Rows:    [][]any{{3}},
+	}
+	if _, err := Encode(table); err == nil {
+		t.Fatal("int 值写入 Int64 列时应报错")
+	}
+}
+
+func TestDecodeRejectsInvalidData(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("PAR1"), []byte("PAR1xxxxxxxxPAR2")} {
+		if _, err := Decode(data); err == nil {
+			t.Errorf("Decode(%q) 应报错", data)
+		}
+	}
+}
+
+// fileWithFooter 把一段 thrift 编码的元数据包装成 Parquet 文件
+func fileWithFooter(footer []byte) []byte {
+	data := append([]byte(magic), footer...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(footer)))
+	return append(data, magic...)
+}
+
+func TestDecodeRejectsInvalidCounts(t *testing.T) {
+	cases := map[string][]byte{
+		// 字段 4（行组列表）中一个行组的 num_rows 为 -1
+		"负数行数": {0x49, 0x1c, 0x36, 0x01, 0x00, 0x00},
+		// num_rows 远大于文件能容纳的行数
+		"过大行数": {0x49, 0x1c, 0x36, 0xfe, 0xff, 0xff, 0xff, 0x0f, 0x00, 0x00},
+		// 字段 2（schema）的列表长度超过剩余字节数
+		"过长列表": {0x29, 0xfc, 0xff, 0xff, 0xff, 0xff, 0x0f, 0x00},
+		// 二进制字段长度超过剩余字节数
+		"过长字符串": {0x18, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x00},
+		// 映射长度超过剩余字节数
+		"过长映射": {0x1b, 0xff, 0xff, 0xff, 0xff, 0x0f, 0x88, 0x00},
+	}
+	for name, footer := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Decode(fileWithFooter(footer)); err == nil {
+				t.Fatal("损坏的计数应报错而不是分配内存")
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Parquet 的元数据使用 Thrift Compact Protocol 编码，这里只实现读写所需的最小子集。
+
+const (
+	tBoolTrue  byte = 1
+	tBoolFalse byte = 2
+	tByte      byte = 3
+	tI16       byte = 4
+	tI32       byte = 5
+	tI64       byte = 6
+	tDouble    byte = 7
+	tBinary    byte = 8
+	tList      byte = 9
+	tSet       byte = 10
+	tMap       byte = 11
+	tStruct    byte = 12
+)
+
+type tField struct {
+	id    int16
+	value any
+}
+
+type tStructValue []tField
+
+type tListValue struct {
+	elemType byte
+	items    []any
+}
+
+type compactWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *compactWriter) writeVarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	w.buf.Write(tmp[:n])
+}
+
+func zigzag64(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func valueType(v any) byte {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return tBoolTrue
+		}
+		return tBoolFalse
+	case int32:
+		return tI32
+	case int64:
+		return tI64
+	case float64:
+		return tDouble
+	case string, []byte:
+		return tBinary
+	case tListValue:
+		return tList
+	case tStructValue:
+		return tStruct
+	default:
+		panic(fmt.Sprintf("parquet: 不支持的 thrift 类型 %T", v))
+	}
+}
+
+func (w *compactWriter) writeStruct(s tStructValue) {
+	var last int16
+	for _, f := range s {
+		typ := valueType(f.value)
+		delta := f.id - last
+		if delta > 0 && delta <= 15 {
+			w.buf.WriteByte(byte(delta)<<4 | typ)
+		} else {
+			w.buf.WriteByte(typ)
+			w.writeVarint(zigzag64(int64(f.id)))
+		}
+		last = f.id
+
+		if _, ok := f.value.(bool); ok {
+			continue
+		}
+		w.writeValue(f.value)
+	}
+	w.buf.WriteByte(0)
+}
+
+func (w *compactWriter) writeValue(v any) {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			w.buf.WriteByte(1)
+		} else {
+			w.buf.WriteByte(2)
+		}
+	case int32:
+		w.writeVarint(zigzag64(int64(val)))
+	case int64:
+		w.writeVarint(zigzag64(val))
+	case float64:
+		var tmp [8]byte
+		binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(val))
+		w.buf.Write(tmp[:])
+	case string:
+		w.writeVarint(uint64(len(val)))
+		w.buf.WriteString(val)
+	case []byte:
+		w.writeVarint(uint64(len(val)))
+		w.buf.Write(val)
+	case tListValue:
+		if len(val.items) < 15 {
+			w.buf.WriteByte(byte(len(val.items))<<4 | val.elemType)
+		} else {
+			w.buf.WriteByte(0xF0 | val.elemType)
+			w.writeVarint(uint64(len(val.items)))
+		}
+		for _, item := range val.items {
+			w.writeValue(item)
+		}
+	case tStructValue:
+		w.writeStruct(val)
+	}
+}
+
+func encodeStruct(s tStructValue) []byte {
+	var w compactWriter
+	w.writeStruct(s)
+	return w.buf.Bytes()
+}
+
+type compactReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *compactReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, fmt.Errorf("parquet: 元数据意外结束")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+// remaining 返回尚未读取的字节数
+func (r *compactReader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+func (r *compactReader) readVarint() (uint64, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("parquet: 无效的 varint")
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *compactReader) readZigzag() (int64, error) {
+	v, err := r.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+// readStruct 读取一个结构体，返回 字段ID -> 值 的映射，未知字段同样保留，调用方按需取用。
+func (r *compactReader) readStruct() (map[int16]any, error) {
+	fields := make(map[int16]any)
+	var last int16
+
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return fields, nil
+		}
+
+		typ := b & 0x0f
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.readZigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		last = id
+
+		switch typ {
+		case tBoolTrue:
+			fields[id] = true
+		case tBoolFalse:
+			fields[id] = false
+		default:
+			v, err := r.readValue(typ)
+			if err != nil {
+				return nil, err
+			}
+			fields[id] = v
+		}
+	}
+}
+
+func (r *compactReader) readValue(typ byte) (any, error) {
+	switch typ {
+	case tBoolTrue, tBoolFalse:
+		b, err := r.readByte()
+		return b == 1, err
+	case tByte:
+		b, err := r.readByte()
+		return int64(int8(b)), err
+	case tI16, tI32, tI64:
+		return r.readZigzag()
+	case tDouble:
+		if r.pos+8 > len(r.data) {
+			return nil, fmt.Errorf("parquet: 元数据意外结束")
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+		r.pos += 8
+		return v, nil
+	case tBinary:
+		n, err := r.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(r.remaining()) {
+			return nil, fmt.Errorf("parquet: 元数据意外结束")
+		}
+		v := r.data[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return v, nil
+	case tList, tSet:
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = r.readVarint(); err != nil {
+				return nil, err
+			}
+		}
+		// 每个元素至少占 1 字节，长度超过剩余字节数时不按它分配内存
+		if size > uint64(r.remaining()) {
+			return nil, fmt.Errorf("parquet: 列表长度无效: %d", size)
+		}
+		elemType := header & 0x0f
+		items := make([]any, 0, size)
+		for i := uint64(0); i < size; i++ {
+			item, err := r.readValue(elemType)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case tMap:
+		size, err := r.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return map[any]any{}, nil
+		}
+		if size > uint64(r.remaining()) {
+			return nil, fmt.Errorf("parquet: 映射长度无效: %d", size)
+		}
+		kv, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[any]any, size)
+		for i := uint64(0); i < size; i++ {
+			k, err := r.readValue(kv >> 4)
+			if err != nil {
+				return nil, err
+			}
+			v, err := r.readValue(kv & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k)] = v
+		}
+		return m, nil
+	case tStruct:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("parquet: 未知的 thrift 类型 %d", typ)
+	}
+}
+
+func fieldInt(fields map[int16]any, id int16) int64 {
+	v, _ := fields[id].(int64)
+	return v
+}
+
+func fieldString(fields map[int16]any, id int16) string {
+	v, _ := fields[id].([]byte)
+	return string(v)
+}
+
+func fieldList(fields map[int16]any, id int16) []any {
+	v, _ := fields[id].([]any)
+	return v
+}
+
+func fieldStruct(fields map[int16]any, id int16) map[int16]any {
+	v, _ := fields[id].(map[int16]any)
+	return v
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
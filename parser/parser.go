@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"encoding/json"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -60,3 +62,69 @@ func (p *VideoParser) ExtractAuthor(html string) string {
 
 	return ""
 }
+
+func (p *VideoParser) ExtractPartition(html string) (int, string) {
+	tid := 0
+	re := regexp.MustCompile(`"tid":(\d+)`)
+	if match := re.FindStringSubmatch(html); len(match) > 1 {
+		tid, _ = strconv.Atoi(match[1])
+	}
+
+	tname := ""
+	re = regexp.MustCompile(`"tname":"([^"]*)"`)
+	if match := re.FindStringSubmatch(html); len(match) > 1 {
+		tname = strings.TrimSpace(match[1])
+	}
+
+	return tid, tname
+}
+
+type VideoStat struct {
+	View     int64 `json:"view"`
+	Danmaku  int64 `json:"danmaku"`
+	Reply    int64 `json:"reply"`
+	Favorite int64 `json:"favorite"`
+	Coin     int64 `json:"coin"`
+	Share    int64 `json:"share"`
+	Like     int64 `json:"like"`
+}
+
+func (p *VideoParser) ExtractStat(html string) VideoStat {
+	var stat VideoStat
+
+	re := regexp.MustCompile(`"stat":(\{[^{}]*\})`)
+	for _, match := range re.FindAllStringSubmatch(html, -1) {
+		if err := json.Unmarshal([]byte(match[1]), &stat); err == nil && stat.View > 0 {
+			return stat
+		}
+	}
+
+	return VideoStat{}
+}
+
+func (p *VideoParser) ExtractOwner(html string) (int64, string) {
+	re := regexp.MustCompile(`"owner":\{"mid":(\d+),"name":"([^"]*)"`)
+	match := re.FindStringSubmatch(html)
+	if len(match) < 3 {
+		return 0, ""
+	}
+
+	mid, _ := strconv.ParseInt(match[1], 10, 64)
+	return mid, strings.TrimSpace(match[2])
+}
+
+// ExtractTagIDs 从页面内嵌的标签数据中提取 标签名 -> tag_id 的映射
+func (p *VideoParser) ExtractTagIDs(html string) map[string]int64 {
+	re := regexp.MustCompile(`"tag_id":(\d+),"tag_name":"([^"]*)"`)
+	ids := make(map[string]int64)
+	for _, match := range re.FindAllStringSubmatch(html, -1) {
+		name := strings.TrimSpace(match[2])
+		if name == "" {
+			continue
+		}
+		if _, ok := ids[name]; !ok {
+			ids[name], _ = strconv.ParseInt(match[1], 10, 64)
+		}
+	}
+	return ids
+}
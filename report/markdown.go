@@ -0,0 +1,126 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"biliTagAnalyse/analyzer"
+	"biliTagAnalyse/statistics"
+)
+
+const defaultAccount = "默认账号"
+
+// MarkdownTag 是 Markdown 模板中 Top Tag 表格的一行，Description 来自模型的 Tag 解读
+type MarkdownTag struct {
+	Rank        int
+	Tag         string
+	Count       int
+	Share       float64
+	Description string
+}
+
+// MarkdownData 是传给 Markdown 模板的数据，自定义模板可以使用其中的全部字段
+type MarkdownData struct {
+	Title       string
+	Result      *analyzer.AnalysisResult
+	Stats       *statistics.StatsResult
+	Accounts    []string
+	Rounds      int
+	StartTime   string
+	EndTime     string
+	TopTags     []MarkdownTag
+	GeneratedAt string
+}
+
+var markdownFuncs = template.FuncMap{
+	"join": strings.Join,
+	"percent": func(v float64) string {
+		return fmt.Sprintf("%.1f%%", v*100)
+	},
+	// cell 转义会破坏表格结构的字符
+	"cell": func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
+
+// WriteMarkdown 使用 text/template 渲染 Markdown 报告，templatePath 为空时使用内置模板。
+func WriteMarkdown(result *analyzer.AnalysisResult, path, templatePath string, topN int) error {
+	if result == nil || result.RawStats == nil {
+		return fmt.Errorf("报告缺少统计数据")
+	}
+	if topN <= 0 {
+		topN = DefaultTopN
+	}
+
+	tmpl := template.New("report.md").Funcs(markdownFuncs)
+	var err error
+	if templatePath != "" {
+		tmpl, err = tmpl.ParseFiles(templatePath)
+		if err == nil {
+			tmpl = tmpl.Lookup(filepath.Base(templatePath))
+		}
+	} else {
+		tmpl, err = tmpl.ParseFS(templates, "templates/report.md")
+	}
+	if err != nil {
+		return fmt.Errorf("解析Markdown模板失败: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, markdownData(result, topN)); err != nil {
+		return fmt.Errorf("渲染Markdown报告失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func markdownData(result *analyzer.AnalysisResult, topN int) *MarkdownData {
+	stats := result.RawStats
+	data := &MarkdownData{
+		Title:       reportTitle(stats),
+		Result:      result,
+		Stats:       stats,
+		Rounds:      roundCount(stats),
+		StartTime:   stats.StartTime,
+		EndTime:     stats.EndTime,
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	account := stats.Label
+	if account == "" {
+		account = defaultAccount
+	}
+	data.Accounts = append(data.Accounts, account)
+	if stats.Baseline != nil {
+		data.Accounts = append(data.Accounts, stats.Baseline.Label)
+	}
+
+	descriptions := make(map[string]string, len(result.TopTags))
+	for _, insight := range result.TopTags {
+		descriptions[insight.Tag] = insight.Description
+	}
+	for _, stat := range head(stats.TagStats, topN) {
+		share := 0.0
+		if stats.TotalVideos > 0 {
+			share = float64(stat.Count) / float64(stats.TotalVideos)
+		}
+		data.TopTags = append(data.TopTags, MarkdownTag{
+			Rank:        stat.Rank,
+			Tag:         stat.Tag,
+			Count:       stat.Count,
+			Share:       share,
+			Description: descriptions[stat.Tag],
+		})
+	}
+
+	return data
+}
@@ -0,0 +1,120 @@
+// Package report 将分析结果渲染为可分享的静态报告。
+package report
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"biliTagAnalyse/analyzer"
+	"biliTagAnalyse/statistics"
+)
+
+//go:embed templates
+var templates embed.FS
+
+const (
+	DefaultTopN      = 20
+	heatmapTags      = 15
+	trendSeries      = 8
+	wordCloudLimit   = 80
+	defaultTitle     = "B站推荐 Tag 分析报告"
+	labelTitleSuffix = " · 推荐 Tag 分析报告"
+)
+
+type htmlData struct {
+	Title       string
+	Result      *analyzer.AnalysisResult
+	Stats       *statistics.StatsResult
+	Rounds      int
+	TopTags     []statistics.TagStat
+	Insights    []analyzer.TagInsight
+	HasAnalysis bool
+	GeneratedAt string
+
+	Bars    template.HTML
+	Cloud   template.HTML
+	Pie     template.HTML
+	Heatmap template.HTML
+	Trend   template.HTML
+}
+
+// WriteHTML 生成单文件 HTML 报告，样式、脚本和图表全部内联，可直接上传到 wiki。
+func WriteHTML(result *analyzer.AnalysisResult, path string, topN int) error {
+	if result == nil || result.RawStats == nil {
+		return fmt.Errorf("报告缺少统计数据")
+	}
+	if topN <= 0 {
+		topN = DefaultTopN
+	}
+
+	tmpl, err := template.ParseFS(templates, "templates/report.html")
+	if err != nil {
+		return fmt.Errorf("解析报告模板失败: %w", err)
+	}
+
+	stats := result.RawStats
+	data := htmlData{
+		Title:       reportTitle(stats),
+		Result:      result,
+		Stats:       stats,
+		Rounds:      roundCount(stats),
+		TopTags:     head(stats.TagStats, topN),
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	data.HasAnalysis = result.Summary != "" || len(result.Trends) > 0 || len(result.Suggestions) > 0
+	for _, insight := range result.TopTags {
+		if insight.Description != "" {
+			data.Insights = append(data.Insights, insight)
+		}
+	}
+
+	data.Bars = barChart(data.TopTags, stats.TotalVideos)
+	data.Cloud = wordCloud(head(stats.TagStats, wordCloudLimit))
+	data.Pie = pieChart(stats.PartitionStats)
+	data.Heatmap = heatmap(head(stats.TagStats, heatmapTags), stats.CoOccurrence)
+	data.Trend = trendChart(stats.TagRounds, trendSeries)
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("渲染报告失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func reportTitle(stats *statistics.StatsResult) string {
+	if stats.Label != "" {
+		return stats.Label + labelTitleSuffix
+	}
+	return defaultTitle
+}
+
+func head(tags []statistics.TagStat, n int) []statistics.TagStat {
+	if len(tags) > n {
+		return tags[:n]
+	}
+	return tags
+}
+
+func roundCount(stats *statistics.StatsResult) int {
+	switch {
+	case stats.TagRounds != nil:
+		return len(stats.TagRounds.Rounds)
+	case stats.PartitionRounds != nil:
+		return len(stats.PartitionRounds.Rounds)
+	case stats.Diversity != nil && len(stats.Diversity.Rounds) > 0:
+		return len(stats.Diversity.Rounds)
+	case stats.TotalVideos > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,294 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+
+	"biliTagAnalyse/statistics"
+)
+
+// 图表全部在服务端生成为内联 SVG，悬停提示使用 <title>，不依赖任何外部脚本库。
+
+var palette = []string{
+	"#fb7299", "#00a1d6", "#f3a034", "#7bc549", "#9b6fd6",
+	"#f25d8e", "#23ade5", "#e5c243", "#4fb39c", "#c56dc9",
+}
+
+func color(i int) string {
+	return palette[i%len(palette)]
+}
+
+func esc(s string) string {
+	return html.EscapeString(s)
+}
+
+func barChart(tags []statistics.TagStat, totalVideos int) template.HTML {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	const (
+		width  = 760
+		labelW = 150
+		rowH   = 24
+		valueW = 60
+	)
+	height := len(tags)*rowH + 10
+	maxCount := tags[0].Count
+	for _, tag := range tags {
+		if tag.Count > maxCount {
+			maxCount = tag.Count
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" role="img">`, width, height)
+	for i, tag := range tags {
+		y := i*rowH + 5
+		w := float64(width-labelW-valueW) * float64(tag.Count) / float64(maxCount)
+		share := 0.0
+		if totalVideos > 0 {
+			share = float64(tag.Count) / float64(totalVideos) * 100
+		}
+		fmt.Fprintf(&b, `<g><title>#%d %s：%d 次（%.1f%% 的视频）</title>`, tag.Rank, esc(tag.Tag), tag.Count, share)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" class="label">%s</text>`, labelW-8, y+16, esc(tag.Tag))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%d" rx="3" fill="%s"/>`, labelW, y+3, w, rowH-6, color(0))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="value">%d</text></g>`, float64(labelW)+w+6, y+16, tag.Count)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func pieChart(partitions []statistics.PartitionStat) template.HTML {
+	if len(partitions) == 0 {
+		return ""
+	}
+
+	const (
+		size   = 240
+		radius = 110
+		limit  = 9
+	)
+
+	// 超出部分合并为“其他”，避免切片过细
+	slices := partitions
+	if len(slices) > limit {
+		other := statistics.PartitionStat{Name: "其他"}
+		for _, p := range slices[limit:] {
+			other.Count += p.Count
+			other.Share += p.Share
+		}
+		slices = append(append([]statistics.PartitionStat{}, slices[:limit]...), other)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<div class="pie"><svg class="chart" viewBox="0 0 %d %d" role="img" style="max-width:%dpx">`, size, size, size)
+	cx, cy := float64(size)/2, float64(size)/2
+	angle := -math.Pi / 2
+	for i, p := range slices {
+		title := fmt.Sprintf(`<title>%s：%d 个视频（%.1f%%）</title>`, esc(p.Name), p.Count, p.Share*100)
+		if p.Share >= 0.9999 {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="%d" fill="%s">%s</circle>`, cx, cy, radius, color(i), title)
+			continue
+		}
+		end := angle + p.Share*2*math.Pi
+		large := 0
+		if p.Share > 0.5 {
+			large = 1
+		}
+		fmt.Fprintf(&b, `<path d="M%.1f,%.1f L%.2f,%.2f A%d,%d 0 %d 1 %.2f,%.2f Z" fill="%s" stroke="#fff">%s</path>`,
+			cx, cy,
+			cx+radius*math.Cos(angle), cy+radius*math.Sin(angle),
+			radius, radius, large,
+			cx+radius*math.Cos(end), cy+radius*math.Sin(end),
+			color(i), title)
+		angle = end
+	}
+	b.WriteString(`</svg><ul class="legend">`)
+	for i, p := range slices {
+		fmt.Fprintf(&b, `<li><span class="swatch" style="background:%s"></span>%s <em>%.1f%%</em></li>`, color(i), esc(p.Name), p.Share*100)
+	}
+	b.WriteString(`</ul></div>`)
+	return template.HTML(b.String())
+}
+
+func heatmap(tags []statistics.TagStat, pairs []statistics.TagPair) template.HTML {
+	if len(tags) < 2 || len(pairs) == 0 {
+		return ""
+	}
+
+	index := make(map[string]int, len(tags))
+	for i, tag := range tags {
+		index[tag.Tag] = i
+	}
+
+	n := len(tags)
+	matrix := make([][]int, n)
+	for i := range matrix {
+		matrix[i] = make([]int, n)
+	}
+	maxCount := 0
+	for _, pair := range pairs {
+		i, okA := index[pair.A]
+		j, okB := index[pair.B]
+		if !okA || !okB {
+			continue
+		}
+		matrix[i][j] = pair.Count
+		matrix[j][i] = pair.Count
+		if pair.Count > maxCount {
+			maxCount = pair.Count
+		}
+	}
+	if maxCount == 0 {
+		return ""
+	}
+
+	const (
+		cell   = 28
+		labelW = 130
+	)
+	width := labelW + n*cell + 10
+	height := labelW + n*cell + 10
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" role="img" style="max-width:%dpx">`, width, height, width)
+	for i, tag := range tags {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" class="label">%s</text>`, labelW-6, labelW+i*cell+18, esc(tag.Tag))
+		x := labelW + i*cell + 18
+		fmt.Fprintf(&b, `<text transform="translate(%d,%d) rotate(-60)" class="label">%s</text>`, x, labelW-6, esc(tag.Tag))
+	}
+	for i := range tags {
+		for j := range tags {
+			x, y := labelW+j*cell, labelW+i*cell
+			if i == j {
+				fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#f1f2f3"/>`, x, y, cell-2, cell-2)
+				continue
+			}
+			opacity := 0.06 + 0.94*float64(matrix[i][j])/float64(maxCount)
+			if matrix[i][j] == 0 {
+				opacity = 0.03
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" fill-opacity="%.2f"><title>%s + %s：%d</title></rect>`,
+				x, y, cell-2, cell-2, color(1), opacity, esc(tags[i].Tag), esc(tags[j].Tag), matrix[i][j])
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func trendChart(matrix *statistics.TagRoundMatrix, limit int) template.HTML {
+	if matrix == nil || len(matrix.Rounds) < 2 || len(matrix.Rows) == 0 {
+		return ""
+	}
+
+	rows := matrix.Rows
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	const (
+		width  = 760
+		height = 300
+		left   = 40
+		right  = 20
+		top    = 15
+		bottom = 30
+	)
+	maxCount := 1
+	for _, row := range rows {
+		for _, c := range row.Counts {
+			if c > maxCount {
+				maxCount = c
+			}
+		}
+	}
+
+	plotW := float64(width - left - right)
+	plotH := float64(height - top - bottom)
+	x := func(i int) float64 {
+		return float64(left) + plotW*float64(i)/float64(len(matrix.Rounds)-1)
+	}
+	y := func(c int) float64 {
+		return float64(top) + plotH*(1-float64(c)/float64(maxCount))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" role="img">`, width, height)
+	for _, tick := range []int{0, maxCount / 2, maxCount} {
+		fmt.Fprintf(&b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="grid"/>`, left, width-right, y(tick), y(tick))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" class="axis">%d</text>`, left-6, y(tick)+4, tick)
+	}
+	for i, round := range matrix.Rounds {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle" class="axis">第%d轮</text>`, x(i), height-8, round)
+	}
+	for r, row := range rows {
+		points := make([]string, len(row.Counts))
+		for i, c := range row.Counts {
+			points[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(c))
+		}
+		fmt.Fprintf(&b, `<g class="series" data-series="%d"><title>%s：%s</title>`, r, esc(row.Tag), joinInts(row.Counts))
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), color(r))
+		for i, c := range row.Counts {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, x(i), y(c), color(r))
+		}
+		b.WriteString(`</g>`)
+	}
+	b.WriteString(`</svg><ul class="legend toggles">`)
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<li data-series="%d"><span class="swatch" style="background:%s"></span>%s</li>`, r, color(r), esc(row.Tag))
+	}
+	b.WriteString(`</ul>`)
+	return template.HTML(b.String())
+}
+
+// wordCloud 用字号和颜色表示频次，按排名交错排列，使大词分布在中间而不是全部挤在开头
+func wordCloud(tags []statistics.TagStat) template.HTML {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	minCount, maxCount := tags[0].Count, tags[0].Count
+	for _, tag := range tags {
+		minCount = min(minCount, tag.Count)
+		maxCount = max(maxCount, tag.Count)
+	}
+
+	var left, right []statistics.TagStat
+	for i, tag := range tags {
+		if i%2 == 0 {
+			right = append(right, tag)
+		} else {
+			left = append(left, tag)
+		}
+	}
+	ordered := make([]statistics.TagStat, 0, len(tags))
+	for i := len(left) - 1; i >= 0; i-- {
+		ordered = append(ordered, left[i])
+	}
+	ordered = append(ordered, right...)
+
+	var b strings.Builder
+	b.WriteString(`<div class="cloud">`)
+	for i, tag := range ordered {
+		weight := 0.0
+		if maxCount > minCount {
+			weight = math.Sqrt(float64(tag.Count-minCount) / float64(maxCount-minCount))
+		}
+		fmt.Fprintf(&b, `<span style="font-size:%.1fpx;color:%s" title="%s：%d">%s</span>`,
+			12+weight*30, color(i), esc(tag.Tag), tag.Count, esc(tag.Tag))
+	}
+	b.WriteString(`</div>`)
+	return template.HTML(b.String())
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, " → ")
+}
@@ -0,0 +1,466 @@
+package statistics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"biliTagAnalyse/crawler"
+	"biliTagAnalyse/parser"
+)
+
+var videoParser = parser.NewVideoParser()
+
+const (
+	SnapshotVersion   = 1
+	coOccurrenceLimit = 100
+)
+
+// VideoRecord 是统计所需的视频精简信息，Tags 为过滤后的结果。
+type VideoRecord struct {
+	Round     int      `json:"round"`
+	Index     int      `json:"index,omitempty"`
+	BVID      string   `json:"bvid,omitempty"`
+	Tags      []string `json:"tags"`
+	TID       int      `json:"tid,omitempty"`
+	TName     string   `json:"tname,omitempty"`
+	MainTID   int      `json:"main_tid,omitempty"`
+	MainName  string   `json:"main_name,omitempty"`
+	AuthorMid int64    `json:"author_mid,omitempty"`
+	Author    string   `json:"author,omitempty"`
+	Views     int64    `json:"views,omitempty"`
+	Likes     int64    `json:"likes,omitempty"`
+	Coins     int64    `json:"coins,omitempty"`
+	Favorites int64    `json:"favorites,omitempty"`
+	CrawledAt string   `json:"crawled_at,omitempty"`
+}
+
+type TagPair struct {
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Count int    `json:"count"`
+}
+
+type Snapshot struct {
+	Version     int            `json:"version"`
+	Label       string         `json:"label,omitempty"`
+	CreatedAt   string         `json:"created_at"`
+	Records     []VideoRecord  `json:"records"`
+	FilterKept  int            `json:"filter_kept"`
+	Filtered    map[string]int `json:"filtered"`
+	FilterCause map[string]int `json:"filter_cause"`
+	Filtering   bool           `json:"filtering"`
+}
+
+type pairKey struct {
+	a, b string
+}
+
+// seenAt 是 Tag 首次出现的位置：轮次、视频在该轮链接列表中的位置、Tag 在视频中的位置。
+// 按位置而不是到达顺序比较，并发爬取时结果也不受请求快慢影响。
+type seenAt struct {
+	round, index, tag int
+}
+
+func (p seenAt) before(q seenAt) bool {
+	if p.round != q.round {
+		return p.round < q.round
+	}
+	if p.index != q.index {
+		return p.index < q.index
+	}
+	return p.tag < q.tag
+}
+
+// Aggregator 在视频到达时更新各项统计的累计量，Result 只做排序和汇总，不再遍历全部视频。
+// 原始记录只在 RetainRecords 之后保留，用于写出快照。
+type Aggregator struct {
+	mu sync.Mutex
+
+	filter    *TagFilter
+	tracker   *filterTracker
+	filtering bool
+	label     string
+
+	retain  bool
+	records []VideoRecord
+	videos  int
+	rounds  int
+
+	tagCounts    map[string]int
+	firstSeen    map[string]seenAt
+	coOccurrence map[pairKey]int
+	// docFreq 为包含每个 Tag 的不同视频数，videoTags 记录每个 BVID 已计入的 Tag
+	docFreq   map[string]int
+	videoTags map[string]map[string]bool
+	tagRounds map[string][]int
+
+	partitions map[partitionKey]*partitionAcc
+	creators   map[string]*creatorAcc
+	engagement map[string]tagMetrics
+	// rounds 之外的多样性与抽样统计只保存每轮的有界摘要和整次运行的视频抽样，不保存逐视频的 Tag 列表
+	samples   []roundSample
+	runSample videoReservoir
+	sampler   *rand.Rand
+
+	firstCrawl, lastCrawl time.Time
+
+	// ranked 缓存 TopTags 的排序结果，有新视频时失效
+	ranked []TagStat
+}
+
+func NewAggregator(filter *TagFilter) *Aggregator {
+	return &Aggregator{
+		filter:       filter,
+		tracker:      newFilterTracker(filter),
+		filtering:    filter != nil,
+		tagCounts:    make(map[string]int),
+		firstSeen:    make(map[string]seenAt),
+		coOccurrence: make(map[pairKey]int),
+		docFreq:      make(map[string]int),
+		videoTags:    make(map[string]map[string]bool),
+		tagRounds:    make(map[string][]int),
+		partitions:   make(map[partitionKey]*partitionAcc),
+		creators:     make(map[string]*creatorAcc),
+		engagement:   make(map[string]tagMetrics),
+		runSample:    videoReservoir{limit: maxSampledVideos},
+		sampler:      rand.New(rand.NewPCG(3, 4)),
+	}
+}
+
+// RetainRecords 让聚合器保留每个视频的精简记录，Snapshot 才会包含 Records。
+// 需要在添加视频之前调用。
+func (a *Aggregator) RetainRecords() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.retain = true
+}
+
+func (a *Aggregator) SetLabel(label string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.label = label
+}
+
+func (a *Aggregator) Add(video *crawler.VideoInfo, round int) {
+	if round <= 0 {
+		round = 1
+	}
+
+	record := VideoRecord{
+		Round:     round,
+		Index:     video.Index,
+		BVID:      videoParser.ExtractBVNumber(video.Link),
+		TID:       video.TID,
+		TName:     video.TName,
+		MainTID:   video.MainTID,
+		MainName:  video.MainName,
+		AuthorMid: video.AuthorMid,
+		Author:    video.Author,
+		Views:     video.Views,
+		Likes:     video.Likes,
+		Coins:     video.Coins,
+		Favorites: video.Favorites,
+		CrawledAt: video.CrawledAt,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, tag := range video.Tags {
+		if a.tracker.keep(tag) {
+			record.Tags = append(record.Tags, tag)
+		}
+	}
+	a.addRecord(record)
+}
+
+// Consume 持续读取视频直到 channel 关闭，轮次取自 VideoInfo.Round。
+func (a *Aggregator) Consume(videos <-chan *crawler.VideoInfo) {
+	for video := range videos {
+		a.Add(video, video.Round)
+	}
+}
+
+func (a *Aggregator) addRecord(record VideoRecord) {
+	if a.retain {
+		a.records = append(a.records, record)
+	}
+	a.videos++
+	a.rounds = max(a.rounds, record.Round)
+	a.ranked = nil
+
+	counted := make(map[string]bool)
+	if record.BVID != "" {
+		if videoTags, ok := a.videoTags[record.BVID]; ok {
+			counted = videoTags
+		} else {
+			a.videoTags[record.BVID] = counted
+		}
+	}
+
+	for i, tag := range record.Tags {
+		at := seenAt{round: record.Round, index: record.Index, tag: i}
+		if prev, ok := a.firstSeen[tag]; !ok || at.before(prev) {
+			a.firstSeen[tag] = at
+		}
+		a.tagCounts[tag]++
+		a.tagRounds[tag] = addRound(a.tagRounds[tag], record.Round)
+		if !counted[tag] {
+			counted[tag] = true
+			a.docFreq[tag]++
+		}
+		for _, other := range record.Tags[i+1:] {
+			key := pairKey{a: tag, b: other}
+			if other < tag {
+				key = pairKey{a: other, b: tag}
+			}
+			a.coOccurrence[key]++
+		}
+	}
+
+	addPartition(a.partitions, record)
+	addCreator(a.creators, record)
+	addEngagement(a.engagement, record)
+
+	for len(a.samples) < record.Round {
+		a.samples = append(a.samples, newRoundSample())
+	}
+	a.samples[record.Round-1].add(record.Tags, mainPartitionKey(record).name, a.sampler)
+	a.runSample.add(record.Tags, a.sampler)
+
+	if t, err := time.Parse(time.RFC3339, record.CrawledAt); err == nil {
+		if a.firstCrawl.IsZero() || t.Before(a.firstCrawl) {
+			a.firstCrawl = t
+		}
+		if t.After(a.lastCrawl) {
+			a.lastCrawl = t
+		}
+	}
+}
+
+func (a *Aggregator) Videos() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.videos
+}
+
+func (a *Aggregator) TopTags(n int) []TagStat {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ranked == nil {
+		a.ranked = rankTags(a.tagCounts, nil, a.firstSeenOrder())
+	}
+	return append([]TagStat(nil), a.ranked[:min(n, len(a.ranked))]...)
+}
+
+// Snapshot 导出当前的统计状态，只有调用过 RetainRecords 时才包含视频记录。
+func (a *Aggregator) Snapshot() *Snapshot {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	snap := &Snapshot{
+		Version:     SnapshotVersion,
+		Label:       a.label,
+		CreatedAt:   time.Now().Format("2006-01-02 15:04:05"),
+		Records:     append([]VideoRecord(nil), a.records...),
+		FilterKept:  a.tracker.kept,
+		Filtered:    make(map[string]int, len(a.tracker.filtered)),
+		FilterCause: make(map[string]int, len(a.tracker.reasons)),
+		Filtering:   a.filtering,
+	}
+	for tag, count := range a.tracker.filtered {
+		snap.Filtered[tag] = count
+	}
+	for reason, count := range a.tracker.reasons {
+		snap.FilterCause[reason] = count
+	}
+	return snap
+}
+
+// Merge 合并其他进程的快照。快照中的 Tag 已按其自身规则过滤，这里不再重复过滤。
+func (a *Aggregator) Merge(snap *Snapshot) error {
+	if snap.Version > SnapshotVersion {
+		return fmt.Errorf("不支持的快照版本: %d", snap.Version)
+	}
+	// 没有保留记录（未调用 RetainRecords）的快照只有过滤计数，合并后 Tag 统计会悄悄缺失
+	if len(snap.Records) == 0 && (snap.FilterKept > 0 || len(snap.Filtered) > 0) {
+		return fmt.Errorf("快照没有视频记录，无法合并 Tag 统计（保存快照的聚合器未保留记录）")
+	}
+	// 轮次从 1 开始，按轮次统计时以 Round-1 为下标，先整体校验再合并，避免合并一半后出错
+	for i, record := range snap.Records {
+		if record.Round < 1 {
+			return fmt.Errorf("快照第 %d 条记录的轮次无效: %d", i+1, record.Round)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.label == "" {
+		a.label = snap.Label
+	}
+	a.tracker.kept += snap.FilterKept
+	for tag, count := range snap.Filtered {
+		a.tracker.filtered[tag] += count
+	}
+	for reason, count := range snap.FilterCause {
+		a.tracker.reasons[reason] += count
+	}
+	a.filtering = a.filtering || snap.Filtering
+
+	for _, record := range snap.Records {
+		a.addRecord(record)
+	}
+	return nil
+}
+
+func (a *Aggregator) Result() *StatsResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	tagStats := rankTags(a.tagCounts, a.docFreq, a.firstSeenOrder())
+	tagRounds := countTagRounds(a.tagRounds, a.rounds, tagStats)
+	partitions, partitionTags, partitionRounds := countPartitions(a.partitions, a.rounds)
+	engagement, weighted := countEngagement(a.engagement)
+	creators, concentration := countCreators(a.creators)
+
+	var filterReport *FilterReport
+	if a.filtering {
+		filterReport = a.tracker.report()
+	}
+
+	now := time.Now()
+	startTime, endTime := a.timeWindow()
+
+	return &StatsResult{
+		RunID:           now.Format("20060102-150405"),
+		Label:           a.label,
+		CrawlTime:       now.Format("2006-01-02 15:04:05"),
+		StartTime:       startTime,
+		EndTime:         endTime,
+		TotalVideos:     a.videos,
+		TotalTags:       len(a.tagCounts),
+		TagStats:        tagStats,
+		FilterReport:    filterReport,
+		CoOccurrence:    a.topPairs(coOccurrenceLimit),
+		TagRounds:       tagRounds,
+		PartitionStats:  partitions,
+		PartitionTags:   partitionTags,
+		PartitionRounds: partitionRounds,
+
+		Engagement:       engagement,
+		WeightedRankings: weighted,
+
+		CreatorStats:         creators,
+		CreatorConcentration: concentration,
+	}
+}
+
+// firstSeenOrder 按首次出现位置为 Tag 编号（从 1 开始）。合并多个快照或旧数据没有视频位置时
+// 不同 Tag 的位置可能相同，此时按字典序。
+func (a *Aggregator) firstSeenOrder() map[string]int {
+	tags := make([]string, 0, len(a.firstSeen))
+	for tag := range a.firstSeen {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		p, q := a.firstSeen[tags[i]], a.firstSeen[tags[j]]
+		if p != q {
+			return p.before(q)
+		}
+		return tags[i] < tags[j]
+	})
+
+	order := make(map[string]int, len(tags))
+	for i, tag := range tags {
+		order[tag] = i + 1
+	}
+	return order
+}
+
+// timeWindow 返回视频爬取时间的最早和最晚值，记录中没有爬取时间（旧快照）时返回空字符串。
+func (a *Aggregator) timeWindow() (string, string) {
+	if a.firstCrawl.IsZero() {
+		return "", ""
+	}
+	return a.firstCrawl.Local().Format("2006-01-02 15:04:05"), a.lastCrawl.Local().Format("2006-01-02 15:04:05")
+}
+
+func (a *Aggregator) topPairs(n int) []TagPair {
+	pairs := make([]TagPair, 0, len(a.coOccurrence))
+	for key, count := range a.coOccurrence {
+		pairs = append(pairs, TagPair{A: key.a, B: key.b, Count: count})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	if len(pairs) > n {
+		pairs = pairs[:n]
+	}
+	return pairs
+}
+
+func (a *Aggregator) Diversity(history *History) *DiversityReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return computeDiversity(a.samples, a.runSummary(), history)
+}
+
+func (a *Aggregator) Sampling(k int) *SamplingReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return computeSampling(a.samples, a.runSummary(), k)
+}
+
+// runSummary 把各轮摘要合成整次运行的摘要，Tag 计数直接使用累计的 tagCounts
+func (a *Aggregator) runSummary() roundSample {
+	run := roundSample{videos: a.videos, tags: a.tagCounts, partitions: make(map[string]int), sample: a.runSample}
+	for _, sample := range a.samples {
+		for name, count := range sample.partitions {
+			run.partitions[name] += count
+		}
+	}
+	return run
+}
+
+func SaveSnapshot(snap *Snapshot, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("序列化快照失败: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("写入快照失败: %w", err)
+	}
+	return nil
+}
+
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照失败: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("解析快照失败: %w", err)
+	}
+	return &snap, nil
+}
@@ -0,0 +1,142 @@
+package statistics
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"biliTagAnalyse/crawler"
+)
+
+func TestMergeRejectsInvalidRound(t *testing.T) {
+	agg := NewAggregator(nil)
+	snap := &Snapshot{
+		Version: SnapshotVersion,
+		Records: []VideoRecord{
+			{Round: 1, Tags: []string{"a"}},
+			{Round: 0, Tags: []string{"a", "b"}},
+		},
+	}
+	if err := agg.Merge(snap); err == nil {
+		t.Fatal("Merge 应拒绝轮次为 0 的记录")
+	}
+	if got := agg.Videos(); got != 0 {
+		t.Fatalf("校验失败时不应合并任何记录，实际合并了 %d 条", got)
+	}
+	agg.Result()
+}
+
+func TestAggregatorRetainsRecordsOnlyWhenAsked(t *testing.T) {
+	records := []VideoRecord{
+		{Round: 1, BVID: "BV1", Tags: []string{"a", "b"}},
+		{Round: 2, BVID: "BV1", Tags: []string{"a"}},
+	}
+
+	agg := NewAggregator(nil)
+	if err := agg.Merge(&Snapshot{Version: SnapshotVersion, Records: records}); err != nil {
+		t.Fatal(err)
+	}
+	if snap := agg.Snapshot(); len(snap.Records) != 0 {
+		t.Fatalf("未调用 RetainRecords 时快照不应包含记录，实际 %d 条", len(snap.Records))
+	}
+
+	result := agg.Result()
+	if result.TotalVideos != 2 || result.TagStats[0].Tag != "a" || result.TagStats[0].DocFreq != 1 {
+		t.Fatalf("统计结果不正确: %+v", result.TagStats)
+	}
+	if got := result.TagRounds.Rows[0].Counts; len(got) != 2 || got[0] != 1 || got[1] != 1 {
+		t.Fatalf("Tag a 的每轮次数应为 [1 1]，实际 %v", got)
+	}
+
+	retained := NewAggregator(nil)
+	retained.RetainRecords()
+	if err := retained.Merge(&Snapshot{Version: SnapshotVersion, Records: records}); err != nil {
+		t.Fatal(err)
+	}
+	if snap := retained.Snapshot(); len(snap.Records) != len(records) {
+		t.Fatalf("快照应包含 %d 条记录，实际 %d 条", len(records), len(snap.Records))
+	}
+}
+
+func TestTopTagsReflectsNewRecords(t *testing.T) {
+	agg := NewAggregator(nil)
+	agg.Merge(&Snapshot{Version: SnapshotVersion, Records: []VideoRecord{{Round: 1, Tags: []string{"a"}}}})
+	if top := agg.TopTags(1); top[0].Tag != "a" {
+		t.Fatalf("Top1 应为 a，实际 %s", top[0].Tag)
+	}
+
+	agg.Merge(&Snapshot{Version: SnapshotVersion, Records: []VideoRecord{
+		{Round: 1, Tags: []string{"b"}},
+		{Round: 1, Tags: []string{"b"}},
+	}})
+	if top := agg.TopTags(1); top[0].Tag != "b" || top[0].Count != 2 {
+		t.Fatalf("Top1 应为出现 2 次的 b，实际 %+v", top[0])
+	}
+}
+
+func TestMergeRejectsSnapshotWithoutRecords(t *testing.T) {
+	// 未调用 RetainRecords，快照中只有过滤计数
+	source := NewAggregator(nil)
+	source.Add(&crawler.VideoInfo{Link: "https://www.bilibili.com/video/BV1xx411c7mD", Tags: []string{"a", "b"}}, 1)
+
+	agg := NewAggregator(nil)
+	if err := agg.Merge(source.Snapshot()); err == nil {
+		t.Fatal("没有视频记录但有过滤计数的快照应报错，而不是只合并计数")
+	}
+
+	// 空快照（例如第一轮就失败）合并后没有任何变化
+	if err := agg.Merge(&Snapshot{Version: SnapshotVersion}); err != nil {
+		t.Fatalf("空快照不应报错: %v", err)
+	}
+}
+
+func TestAggregatorSamplesAreBounded(t *testing.T) {
+	agg := NewAggregator(nil)
+	const videos = maxSampledVideos*2 + 7
+	for i := 0; i < videos; i++ {
+		tags := []string{"common", fmt.Sprintf("t%d", i%50)}
+		agg.addRecord(VideoRecord{Round: 1 + i%2, Index: i, Tags: tags})
+	}
+
+	for i, sample := range agg.samples {
+		if len(sample.sample.items) > maxSampledVideos {
+			t.Errorf("第 %d 轮保留了 %d 个视频的 Tag 列表，超过上限 %d", i+1, len(sample.sample.items), maxSampledVideos)
+		}
+	}
+	if len(agg.runSample.items) != maxSampledVideos || agg.runSample.seen != videos {
+		t.Errorf("整次运行的样本 = %d/%d", len(agg.runSample.items), agg.runSample.seen)
+	}
+
+	// 计数类指标仍基于全部视频，抽样只影响列表内相似度和置信区间
+	diversity := agg.Diversity(nil)
+	if diversity.Run.Videos != videos || diversity.Run.DistinctTags != 51 {
+		t.Errorf("整次运行的多样性 = %+v", diversity.Run)
+	}
+	if diversity.Rounds[0].Videos+diversity.Rounds[1].Videos != videos {
+		t.Errorf("每轮视频数之和应为 %d: %+v", videos, diversity.Rounds)
+	}
+	sampling := agg.Sampling(5)
+	if sampling.Videos != videos || sampling.ObservedTags != 51 || len(sampling.Accumulation) != 2 {
+		t.Errorf("抽样报告 = %+v", sampling)
+	}
+	if ci := sampling.ShareCI[0]; ci.Tag != "common" || ci.Share != 1 || ci.Lower != 1 || ci.Upper != 1 {
+		t.Errorf("每个视频都有的 Tag 占比应为 1: %+v", ci)
+	}
+}
+
+func TestVideoReservoirKeepsAllBelowLimit(t *testing.T) {
+	r := videoReservoir{limit: 3}
+	rng := rand.New(rand.NewPCG(1, 1))
+	for _, tag := range []string{"a", "b", "c"} {
+		r.add([]string{tag}, rng)
+	}
+	if len(r.items) != 3 || r.items[0][0] != "a" || r.items[2][0] != "c" {
+		t.Fatalf("未超过上限时应按顺序保留全部: %v", r.items)
+	}
+	for i := 0; i < 100; i++ {
+		r.add([]string{"x"}, rng)
+	}
+	if len(r.items) != 3 || r.seen != 103 {
+		t.Fatalf("超过上限后样本大小应保持为 3: %v (%d)", r.items, r.seen)
+	}
+}
@@ -0,0 +1,53 @@
+package statistics
+
+import "sort"
+
+const (
+	// UncategorizedLabel 是没有分类结果的 Tag 的归属
+	UncategorizedLabel = "未分类"
+	categoryTopTags    = 5
+)
+
+type CategoryStat struct {
+	Category string   `json:"category"`
+	Tags     int      `json:"tags"`
+	Count    int      `json:"count"`
+	Share    float64  `json:"share"`
+	TopTags  []string `json:"top_tags"`
+}
+
+// RollupCategories 按 Tag 分类汇总出现次数，assign 中没有的 Tag 计入“未分类”。
+// tagStats 需已按出现次数降序排列，结果按出现次数降序排列。
+func RollupCategories(tagStats []TagStat, assign map[string]string) []CategoryStat {
+	index := make(map[string]int)
+	var stats []CategoryStat
+	total := 0
+
+	for _, tag := range tagStats {
+		category := assign[tag.Tag]
+		if category == "" {
+			category = UncategorizedLabel
+		}
+
+		i, ok := index[category]
+		if !ok {
+			i = len(stats)
+			index[category] = i
+			stats = append(stats, CategoryStat{Category: category})
+		}
+		stats[i].Tags++
+		stats[i].Count += tag.Count
+		if len(stats[i].TopTags) < categoryTopTags {
+			stats[i].TopTags = append(stats[i].TopTags, tag.Tag)
+		}
+		total += tag.Count
+	}
+
+	for i := range stats {
+		if total > 0 {
+			stats[i].Share = float64(stats[i].Count) / float64(total)
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Count > stats[j].Count })
+	return stats
+}
@@ -0,0 +1,40 @@
+package statistics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRollupCategories(t *testing.T) {
+	tags := []TagStat{
+		{Tag: "原神", Count: 10},
+		{Tag: "翻唱", Count: 8},
+		{Tag: "王者荣耀", Count: 6},
+		{Tag: "长尾", Count: 1},
+	}
+	assign := map[string]string{"原神": "游戏", "王者荣耀": "游戏", "翻唱": "音乐"}
+
+	got := RollupCategories(tags, assign)
+	want := []CategoryStat{
+		{Category: "游戏", Tags: 2, Count: 16, Share: 16.0 / 25, TopTags: []string{"原神", "王者荣耀"}},
+		{Category: "音乐", Tags: 1, Count: 8, Share: 8.0 / 25, TopTags: []string{"翻唱"}},
+		{Category: UncategorizedLabel, Tags: 1, Count: 1, Share: 1.0 / 25, TopTags: []string{"长尾"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RollupCategories:\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestRollupCategoriesTopTagsLimit(t *testing.T) {
+	var tags []TagStat
+	for _, tag := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		tags = append(tags, TagStat{Tag: tag, Count: 1})
+	}
+	got := RollupCategories(tags, nil)
+	if len(got) != 1 || got[0].Tags != 7 || len(got[0].TopTags) != categoryTopTags || got[0].Share != 1 {
+		t.Errorf("全部未分类时应汇总为一项且只列出前 %d 个 Tag: %+v", categoryTopTags, got)
+	}
+	if RollupCategories(nil, nil) != nil {
+		t.Error("空列表应返回 nil")
+	}
+}
@@ -0,0 +1,146 @@
+package statistics
+
+import (
+	"math"
+	"sort"
+)
+
+// TagCluster 是语义相近的一组 Tag，Label 为簇的中心点（与其余成员平均相似度最高的 Tag），
+// Count 为成员出现次数之和。只有一个成员的簇不列出 Members。
+type TagCluster struct {
+	Label   string    `json:"label"`
+	Count   int       `json:"count"`
+	Members []TagStat `json:"members,omitempty"`
+}
+
+// ClusterTags 对 Tag 向量做平均链接层次聚类：反复合并平均余弦相似度最高的两个簇，直到最高相似度低于 threshold。
+// vectors[i] 对应 tags[i]，缺少向量的 Tag 单独成簇。结果按合并后的出现次数降序排列。
+func ClusterTags(tags []TagStat, vectors [][]float64, threshold float64) []TagCluster {
+	var points []int
+	var normalized [][]float64
+	for i := range tags {
+		if i < len(vectors) {
+			if v := normalize(vectors[i]); v != nil {
+				points = append(points, i)
+				normalized = append(normalized, v)
+			}
+		}
+	}
+
+	n := len(points)
+	sim := make([][]float64, n)
+	for i := range sim {
+		sim[i] = make([]float64, n)
+		for j := 0; j < i; j++ {
+			sim[i][j] = dot(normalized[i], normalized[j])
+			sim[j][i] = sim[i][j]
+		}
+	}
+
+	// members[i] 为第 i 个簇包含的点，合并后被吸收的簇置为 nil
+	members := make([][]int, n)
+	for i := range members {
+		members[i] = []int{i}
+	}
+	for {
+		bestA, bestB, best := -1, -1, threshold
+		for a := 0; a < n; a++ {
+			if members[a] == nil {
+				continue
+			}
+			for b := a + 1; b < n; b++ {
+				if members[b] != nil && sim[a][b] >= best {
+					bestA, bestB, best = a, b, sim[a][b]
+				}
+			}
+		}
+		if bestA < 0 {
+			break
+		}
+
+		// Lance-Williams 更新：新簇与其他簇的平均链接相似度按簇大小加权
+		sizeA, sizeB := float64(len(members[bestA])), float64(len(members[bestB]))
+		for k := 0; k < n; k++ {
+			if members[k] == nil || k == bestA || k == bestB {
+				continue
+			}
+			s := (sizeA*sim[bestA][k] + sizeB*sim[bestB][k]) / (sizeA + sizeB)
+			sim[bestA][k], sim[k][bestA] = s, s
+		}
+		members[bestA] = append(members[bestA], members[bestB]...)
+		members[bestB] = nil
+	}
+
+	clustered := make(map[int]bool, n)
+	var clusters []TagCluster
+	for _, group := range members {
+		if group == nil {
+			continue
+		}
+		indexes := make([]int, len(group))
+		for i, p := range group {
+			indexes[i] = points[p]
+			clustered[points[p]] = true
+		}
+		clusters = append(clusters, newTagCluster(tags, indexes, medoid(normalized, group)))
+	}
+	for i, tag := range tags {
+		if !clustered[i] {
+			clusters = append(clusters, TagCluster{Label: tag.Tag, Count: tag.Count})
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Count > clusters[j].Count })
+	return clusters
+}
+
+// medoid 返回与其余成员相似度之和最高的成员在 group 中的下标，相同时取靠前（出现次数更多）的成员
+func medoid(vectors [][]float64, group []int) int {
+	best, bestScore := 0, math.Inf(-1)
+	for i, a := range group {
+		score := 0.0
+		for _, b := range group {
+			if a != b {
+				score += dot(vectors[a], vectors[b])
+			}
+		}
+		if score > bestScore+1e-9 || (math.Abs(score-bestScore) <= 1e-9 && a < group[best]) {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+func newTagCluster(tags []TagStat, indexes []int, center int) TagCluster {
+	cluster := TagCluster{Label: tags[indexes[center]].Tag}
+	for _, i := range indexes {
+		cluster.Count += tags[i].Count
+	}
+	if len(indexes) > 1 {
+		sort.Ints(indexes)
+		for _, i := range indexes {
+			cluster.Members = append(cluster.Members, tags[i])
+		}
+	}
+	return cluster
+}
+
+func normalize(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return nil
+	}
+	out := make([]float64, len(v))
+	for i := range v {
+		out[i] = v[i] / norm
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := 0; i < len(a) && i < len(b); i++ {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
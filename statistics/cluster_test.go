@@ -0,0 +1,50 @@
+package statistics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusterTags(t *testing.T) {
+	tags := []TagStat{
+		{Tag: "我的世界", Count: 10},
+		{Tag: "音乐", Count: 8},
+		{Tag: "MC", Count: 5},
+		{Tag: "minecraft", Count: 3},
+		{Tag: "长尾", Count: 1},
+	}
+	vectors := [][]float64{
+		{1, 0},
+		{0, 1},
+		{1, 0.05},
+		{0.98, 0.1},
+		// 长尾没有向量，单独成簇
+	}
+
+	got := ClusterTags(tags, vectors, 0.9)
+	// MC 位于另外两个成员之间，与其余成员的相似度之和最高，作为簇的标签
+	want := []TagCluster{
+		{Label: "MC", Count: 18, Members: []TagStat{tags[0], tags[2], tags[3]}},
+		{Label: "音乐", Count: 8},
+		{Label: "长尾", Count: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ClusterTags:\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestClusterTagsThreshold(t *testing.T) {
+	tags := []TagStat{{Tag: "a", Count: 2}, {Tag: "b", Count: 1}}
+	vectors := [][]float64{{1, 0}, {0.8, 0.6}} // 余弦相似度 0.8
+
+	if got := ClusterTags(tags, vectors, 0.85); len(got) != 2 {
+		t.Errorf("相似度低于阈值时不应合并: %+v", got)
+	}
+	if got := ClusterTags(tags, vectors, 0.75); len(got) != 1 || got[0].Count != 3 {
+		t.Errorf("相似度高于阈值时应合并: %+v", got)
+	}
+	// 零向量无法归一化，视为没有向量
+	if got := ClusterTags(tags, [][]float64{{0, 0}, {0, 0}}, 0.1); len(got) != 2 {
+		t.Errorf("零向量不应参与聚类: %+v", got)
+	}
+}
@@ -0,0 +1,330 @@
+package statistics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"biliTagAnalyse/crawler"
+)
+
+const (
+	DefaultCompareTopK = 50
+	compareUniqueTop   = 20
+)
+
+type LabeledStats struct {
+	Label string
+	Stats *StatsResult
+}
+
+type SideBySideRow struct {
+	Rank    int       `json:"rank"`
+	Entries []TagStat `json:"entries"`
+}
+
+type CompareResult struct {
+	Accounts   []string             `json:"accounts"`
+	TopK       int                  `json:"top_k"`
+	Jaccard    [][]float64          `json:"jaccard"`
+	Cosine     [][]float64          `json:"cosine"`
+	Spearman   [][]float64          `json:"spearman"`
+	Kendall    [][]float64          `json:"kendall"`
+	UniqueTags map[string][]TagStat `json:"unique_tags"`
+	SideBySide []SideBySideRow      `json:"side_by_side"`
+}
+
+// LoadDataset 读取统计结果（tags_stats.json / analysis_result.json）、统计快照或原始视频数据（.jsonl）。
+// 统计结果和快照在写入时已经过滤，原始视频数据按 filter 重新过滤，对比时各数据集使用相同的口径。
+func LoadDataset(path string, filter *TagFilter) (*StatsResult, error) {
+	if strings.EqualFold(filepath.Ext(path), ".jsonl") {
+		allVideos, err := crawler.LoadVideos(path)
+		if err != nil {
+			return nil, err
+		}
+		return CountTagsMultipleRounds(allVideos, filter), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	var wrapper struct {
+		RawStats *StatsResult  `json:"raw_stats"`
+		Records  []VideoRecord `json:"records"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err == nil {
+		if wrapper.RawStats != nil {
+			return wrapper.RawStats, nil
+		}
+		if wrapper.Records != nil {
+			var snap Snapshot
+			if err := json.Unmarshal(data, &snap); err != nil {
+				return nil, fmt.Errorf("解析快照失败: %w", err)
+			}
+			agg := NewAggregator(nil)
+			if err := agg.Merge(&snap); err != nil {
+				return nil, err
+			}
+			return agg.Result(), nil
+		}
+	}
+
+	var stats StatsResult
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %w", err)
+	}
+	return &stats, nil
+}
+
+func topK(stats *StatsResult, k int) []TagStat {
+	if len(stats.TagStats) < k {
+		return stats.TagStats
+	}
+	return stats.TagStats[:k]
+}
+
+func tagVector(stats *StatsResult) map[string]float64 {
+	vec := make(map[string]float64, len(stats.TagStats))
+	for _, stat := range stats.TagStats {
+		vec[stat.Tag] = float64(stat.Count)
+	}
+	return vec
+}
+
+func tagSet(stats []TagStat) map[string]bool {
+	set := make(map[string]bool, len(stats))
+	for _, stat := range stats {
+		set[stat.Tag] = true
+	}
+	return set
+}
+
+func cosineSimilarity(a, b map[string]float64) float64 {
+	dot, normA, normB := 0.0, 0.0, 0.0
+	for tag, v := range a {
+		normA += v * v
+		dot += v * b[tag]
+	}
+	for _, v := range b {
+		normB += v * v
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// rankVectors 以两个列表 Top K 的并集为样本，未进入某个列表 Top K 的 Tag 视为并列第 K+1 名。
+func rankVectors(a, b []TagStat) ([]float64, []float64) {
+	rankA := averageRanks(a)
+	rankB := averageRanks(b)
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, list := range [][]TagStat{a, b} {
+		for _, stat := range list {
+			if !seen[stat.Tag] {
+				seen[stat.Tag] = true
+				tags = append(tags, stat.Tag)
+			}
+		}
+	}
+
+	missA := float64(len(a) + 1)
+	missB := float64(len(b) + 1)
+	x := make([]float64, len(tags))
+	y := make([]float64, len(tags))
+	for i, tag := range tags {
+		x[i], y[i] = missA, missB
+		if r, ok := rankA[tag]; ok {
+			x[i] = r
+		}
+		if r, ok := rankB[tag]; ok {
+			y[i] = r
+		}
+	}
+	return x, y
+}
+
+// averageRanks 为相同次数的 Tag 分配平均名次。
+func averageRanks(stats []TagStat) map[string]float64 {
+	ranks := make(map[string]float64, len(stats))
+	for i := 0; i < len(stats); {
+		j := i
+		for j < len(stats) && stats[j].Count == stats[i].Count {
+			j++
+		}
+		avg := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			ranks[stats[k].Tag] = avg
+		}
+		i = j
+	}
+	return ranks
+}
+
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	if n < 2 {
+		return 0
+	}
+
+	meanX, meanY := 0.0, 0.0
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	cov, varX, varY := 0.0, 0.0, 0.0
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+// kendallTauB 计算考虑并列的 Kendall τ-b。
+func kendallTauB(x, y []float64) float64 {
+	concordant, discordant, tiesX, tiesY := 0.0, 0.0, 0.0, 0.0
+	for i := 0; i < len(x); i++ {
+		for j := i + 1; j < len(x); j++ {
+			dx := x[i] - x[j]
+			dy := y[i] - y[j]
+			switch {
+			case dx == 0 && dy == 0:
+			case dx == 0:
+				tiesX++
+			case dy == 0:
+				tiesY++
+			case dx*dy > 0:
+				concordant++
+			default:
+				discordant++
+			}
+		}
+	}
+
+	denom := math.Sqrt((concordant + discordant + tiesX) * (concordant + discordant + tiesY))
+	if denom == 0 {
+		return 0
+	}
+	return (concordant - discordant) / denom
+}
+
+func newMatrix(n int) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+	}
+	return m
+}
+
+func CompareFeeds(datasets []LabeledStats, k int) *CompareResult {
+	if k <= 0 {
+		k = DefaultCompareTopK
+	}
+
+	n := len(datasets)
+	result := &CompareResult{
+		TopK:       k,
+		Jaccard:    newMatrix(n),
+		Cosine:     newMatrix(n),
+		Spearman:   newMatrix(n),
+		Kendall:    newMatrix(n),
+		UniqueTags: make(map[string][]TagStat, n),
+	}
+
+	tops := make([][]TagStat, n)
+	sets := make([]map[string]bool, n)
+	vectors := make([]map[string]float64, n)
+	for i, ds := range datasets {
+		result.Accounts = append(result.Accounts, ds.Label)
+		tops[i] = topK(ds.Stats, k)
+		sets[i] = tagSet(ds.Stats.TagStats)
+		vectors[i] = tagVector(ds.Stats)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i == j {
+				result.Jaccard[i][j] = 1
+				result.Cosine[i][j] = 1
+				result.Spearman[i][j] = 1
+				result.Kendall[i][j] = 1
+				continue
+			}
+			result.Jaccard[i][j] = jaccard(sets[i], sets[j])
+			result.Cosine[i][j] = cosineSimilarity(vectors[i], vectors[j])
+			x, y := rankVectors(tops[i], tops[j])
+			result.Spearman[i][j] = pearson(x, y)
+			result.Kendall[i][j] = kendallTauB(x, y)
+		}
+	}
+
+	for i, ds := range datasets {
+		unique := make([]TagStat, 0)
+		for _, stat := range ds.Stats.TagStats {
+			shared := false
+			for j := range datasets {
+				if j != i && sets[j][stat.Tag] {
+					shared = true
+					break
+				}
+			}
+			if !shared {
+				unique = append(unique, stat)
+			}
+			if len(unique) >= compareUniqueTop {
+				break
+			}
+		}
+		result.UniqueTags[ds.Label] = unique
+	}
+
+	rows := 0
+	for _, top := range tops {
+		if len(top) > rows {
+			rows = len(top)
+		}
+	}
+	for r := 0; r < rows; r++ {
+		row := SideBySideRow{Rank: r + 1, Entries: make([]TagStat, n)}
+		for i, top := range tops {
+			if r < len(top) {
+				row.Entries[i] = top[r]
+			}
+		}
+		result.SideBySide = append(result.SideBySide, row)
+	}
+
+	return result
+}
+
+func SaveCompareResult(result *CompareResult, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化结果失败: %w", err)
+	}
+
+	if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package statistics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDatasetFiltersRawVideos(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.jsonl")
+	lines := `{"round":1,"link":"https://www.bilibili.com/video/BV1aa411c7mD","tags":["活动","游戏"]}
+{"round":1,"link":"https://www.bilibili.com/video/BV1bb411c7mD","tags":["活动","音乐"]}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	filter, err := NewTagFilter(TagFilterConfig{DisableDefaultStopList: true, StopExact: []string{"活动"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := LoadDataset(path, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalTags != 2 {
+		t.Fatalf("过滤后应剩 2 个 Tag，实际 %+v", stats.TagStats)
+	}
+	for _, tag := range stats.TagStats {
+		if tag.Tag == "活动" {
+			t.Fatalf("原始视频数据应按 tag_filter 过滤: %+v", stats.TagStats)
+		}
+	}
+	if stats.FilterReport == nil || stats.FilterReport.FilteredOccurrences != 2 {
+		t.Errorf("过滤报告应记录 2 次被过滤的出现，实际 %+v", stats.FilterReport)
+	}
+}
@@ -0,0 +1,141 @@
+package statistics
+
+import (
+	"sort"
+	"strconv"
+)
+
+const (
+	creatorTopN    = 10
+	creatorTopTags = 5
+)
+
+type CreatorStat struct {
+	Mid          int64    `json:"mid"`
+	Name         string   `json:"name"`
+	Videos       int      `json:"videos"`
+	DistinctTags int      `json:"distinct_tags"`
+	Share        float64  `json:"share"`
+	TopTags      []string `json:"top_tags"`
+}
+
+type CreatorConcentration struct {
+	Creators  int     `json:"creators"`
+	TopN      int     `json:"top_n"`
+	TopNShare float64 `json:"top_n_share"`
+	HHI       float64 `json:"hhi"`
+	Gini      float64 `json:"gini"`
+}
+
+type creatorAcc struct {
+	mid    int64
+	name   string
+	videos int
+	tags   map[string]int
+}
+
+func creatorKey(video VideoRecord) string {
+	if video.AuthorMid != 0 {
+		return strconv.FormatInt(video.AuthorMid, 10)
+	}
+	return video.Author
+}
+
+// addCreator 把一条视频记录计入其 UP 主，没有作者信息的视频不计入
+func addCreator(accs map[string]*creatorAcc, video VideoRecord) {
+	key := creatorKey(video)
+	if key == "" {
+		return
+	}
+
+	acc, ok := accs[key]
+	if !ok {
+		acc = &creatorAcc{mid: video.AuthorMid, tags: make(map[string]int)}
+		accs[key] = acc
+	}
+	if acc.name == "" {
+		acc.name = video.Author
+	}
+	acc.videos++
+	for _, tag := range video.Tags {
+		acc.tags[tag]++
+	}
+}
+
+func countCreators(accs map[string]*creatorAcc) ([]CreatorStat, *CreatorConcentration) {
+	total := 0
+	for _, acc := range accs {
+		total += acc.videos
+	}
+
+	if total == 0 {
+		return nil, nil
+	}
+
+	creators := make([]CreatorStat, 0, len(accs))
+	for _, acc := range accs {
+		stat := CreatorStat{
+			Mid:          acc.mid,
+			Name:         acc.name,
+			Videos:       acc.videos,
+			DistinctTags: len(acc.tags),
+			Share:        float64(acc.videos) / float64(total),
+		}
+
+		tags := make([]TagStat, 0, len(acc.tags))
+		for tag, count := range acc.tags {
+			tags = append(tags, TagStat{Tag: tag, Count: count})
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			if tags[i].Count != tags[j].Count {
+				return tags[i].Count > tags[j].Count
+			}
+			return tags[i].Tag < tags[j].Tag
+		})
+		for i := 0; i < len(tags) && i < creatorTopTags; i++ {
+			stat.TopTags = append(stat.TopTags, tags[i].Tag)
+		}
+
+		creators = append(creators, stat)
+	}
+
+	sort.Slice(creators, func(i, j int) bool {
+		if creators[i].Videos != creators[j].Videos {
+			return creators[i].Videos > creators[j].Videos
+		}
+		if creators[i].Mid != creators[j].Mid {
+			return creators[i].Mid < creators[j].Mid
+		}
+		return creators[i].Name < creators[j].Name
+	})
+
+	return creators, creatorConcentration(creators)
+}
+
+func creatorConcentration(creators []CreatorStat) *CreatorConcentration {
+	c := &CreatorConcentration{
+		Creators: len(creators),
+		TopN:     creatorTopN,
+	}
+
+	for i, creator := range creators {
+		if i < creatorTopN {
+			c.TopNShare += creator.Share
+		}
+		c.HHI += creator.Share * creator.Share
+	}
+
+	// creators 按视频数降序，Gini 需要升序累加
+	n := float64(len(creators))
+	sum, weighted := 0.0, 0.0
+	for i := len(creators) - 1; i >= 0; i-- {
+		rank := n - float64(i)
+		sum += float64(creators[i].Videos)
+		weighted += rank * float64(creators[i].Videos)
+	}
+	if sum > 0 && n > 1 {
+		c.Gini = (2*weighted)/(n*sum) - (n+1)/n
+	}
+
+	return c
+}
@@ -0,0 +1,200 @@
+package statistics
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+const (
+	diversityHistorySize = 10
+	// maxSampledVideos 是每轮以及整次运行保留 Tag 列表的视频数上限，超出后按蓄水池抽样替换
+	maxSampledVideos = 500
+)
+
+type DiversityMetrics struct {
+	Round               int     `json:"round,omitempty"`
+	Videos              int     `json:"videos"`
+	DistinctTags        int     `json:"distinct_tags"`
+	TagEntropy          float64 `json:"tag_entropy"`
+	TagSimpson          float64 `json:"tag_simpson"`
+	PartitionEntropy    float64 `json:"partition_entropy"`
+	PartitionSimpson    float64 `json:"partition_simpson"`
+	Novelty             float64 `json:"novelty"`
+	IntraListSimilarity float64 `json:"intra_list_similarity"`
+}
+
+type DiversityReport struct {
+	Run     DiversityMetrics   `json:"run"`
+	Rounds  []DiversityMetrics `json:"rounds"`
+	History []HistoryPoint     `json:"history,omitempty"`
+}
+
+// shannonEntropy 以 bit 为单位。
+func shannonEntropy(counts map[string]int) float64 {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+
+	h := 0.0
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / float64(total)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// simpsonIndex 返回 Gini-Simpson 多样性 1-Σp²，越大越多样。
+func simpsonIndex(counts map[string]int) float64 {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		sum += p * p
+	}
+	return 1 - sum
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for tag := range a {
+		if b[tag] {
+			inter++
+		}
+	}
+	union := len(a) + len(b) - inter
+	return float64(inter) / float64(union)
+}
+
+// intraListSimilarity 为列表内视频两两之间 Tag 集合 Jaccard 相似度的均值，越大说明推荐越同质。
+func intraListSimilarity(tagSets []map[string]bool) float64 {
+	if len(tagSets) < 2 {
+		return 0
+	}
+
+	sum := 0.0
+	pairs := 0
+	for i := 0; i < len(tagSets); i++ {
+		for j := i + 1; j < len(tagSets); j++ {
+			sum += jaccard(tagSets[i], tagSets[j])
+			pairs++
+		}
+	}
+	return sum / float64(pairs)
+}
+
+// videoReservoir 用蓄水池抽样保留至多 limit 个视频的 Tag 列表，每个视频被保留的概率相同，
+// 内存不随视频数增长。视频数不超过 limit 时保留全部，结果与逐视频计算完全一致。
+type videoReservoir struct {
+	limit int
+	seen  int
+	items [][]string
+}
+
+func (r *videoReservoir) add(tags []string, rng *rand.Rand) {
+	r.seen++
+	if len(r.items) < r.limit {
+		r.items = append(r.items, tags)
+		return
+	}
+	if j := rng.IntN(r.seen); j < r.limit {
+		r.items[j] = tags
+	}
+}
+
+// roundSample 是一轮推荐的有界摘要：Tag 和分区的出现次数，以及用于列表内相似度和 bootstrap 的视频抽样
+type roundSample struct {
+	videos     int
+	tags       map[string]int
+	partitions map[string]int
+	sample     videoReservoir
+}
+
+func newRoundSample() roundSample {
+	return roundSample{
+		tags:       make(map[string]int),
+		partitions: make(map[string]int),
+		sample:     videoReservoir{limit: maxSampledVideos},
+	}
+}
+
+func (s *roundSample) add(tags []string, partition string, rng *rand.Rand) {
+	s.videos++
+	for _, tag := range tags {
+		s.tags[tag]++
+	}
+	s.partitions[partition]++
+	s.sample.add(tags, rng)
+}
+
+func computeDiversityMetrics(s roundSample, seen map[string]bool) DiversityMetrics {
+	tagSets := make([]map[string]bool, 0, len(s.sample.items))
+	for _, tags := range s.sample.items {
+		set := make(map[string]bool)
+		for _, tag := range tags {
+			set[tag] = true
+		}
+		tagSets = append(tagSets, set)
+	}
+
+	novel := 0
+	for tag := range s.tags {
+		if !seen[tag] {
+			novel++
+		}
+	}
+
+	m := DiversityMetrics{
+		Videos:              s.videos,
+		DistinctTags:        len(s.tags),
+		TagEntropy:          shannonEntropy(s.tags),
+		TagSimpson:          simpsonIndex(s.tags),
+		PartitionEntropy:    shannonEntropy(s.partitions),
+		PartitionSimpson:    simpsonIndex(s.partitions),
+		IntraListSimilarity: intraListSimilarity(tagSets),
+	}
+	if len(s.tags) > 0 {
+		m.Novelty = float64(novel) / float64(len(s.tags))
+	}
+	return m
+}
+
+// computeDiversity 计算每轮及整次运行（run）的多样性指标。
+// 每轮的新颖度相对于历史运行和本次之前的轮次，整次运行的新颖度仅相对于历史运行。
+func computeDiversity(rounds []roundSample, run roundSample, history *History) *DiversityReport {
+	priorTags := history.SeenTags()
+	seen := make(map[string]bool, len(priorTags))
+	for tag := range priorTags {
+		seen[tag] = true
+	}
+
+	report := &DiversityReport{History: history.Recent(diversityHistorySize)}
+	for i, sample := range rounds {
+		m := computeDiversityMetrics(sample, seen)
+		m.Round = i + 1
+		report.Rounds = append(report.Rounds, m)
+
+		for tag := range sample.tags {
+			seen[tag] = true
+		}
+	}
+
+	report.Run = computeDiversityMetrics(run, priorTags)
+	return report
+}
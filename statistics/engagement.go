@@ -0,0 +1,196 @@
+package statistics
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	MetricViews          = "views"
+	MetricLikes          = "likes"
+	MetricCoins          = "coins"
+	MetricFavorites      = "favorites"
+	MetricEngagementRate = "engagement_rate"
+)
+
+const weightedRankingSize = 20
+
+var engagementMetrics = []string{MetricViews, MetricLikes, MetricCoins, MetricFavorites, MetricEngagementRate}
+
+type MetricSummary struct {
+	Sum    float64 `json:"sum"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P25    float64 `json:"p25"`
+	P75    float64 `json:"p75"`
+	P90    float64 `json:"p90"`
+}
+
+type TagEngagement struct {
+	Tag            string        `json:"tag"`
+	Videos         int           `json:"videos"`
+	Views          MetricSummary `json:"views"`
+	Likes          MetricSummary `json:"likes"`
+	Coins          MetricSummary `json:"coins"`
+	Favorites      MetricSummary `json:"favorites"`
+	EngagementRate MetricSummary `json:"engagement_rate"`
+}
+
+type WeightedTagStat struct {
+	Tag    string  `json:"tag"`
+	Weight float64 `json:"weight"`
+	Videos int     `json:"videos"`
+}
+
+func videoMetric(video VideoRecord, metric string) float64 {
+	switch metric {
+	case MetricViews:
+		return float64(video.Views)
+	case MetricLikes:
+		return float64(video.Likes)
+	case MetricCoins:
+		return float64(video.Coins)
+	case MetricFavorites:
+		return float64(video.Favorites)
+	case MetricEngagementRate:
+		if video.Views == 0 {
+			return 0
+		}
+		return float64(video.Likes) / float64(video.Views)
+	default:
+		return 0
+	}
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	frac := pos - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
+
+func summarize(values []float64) MetricSummary {
+	if len(values) == 0 {
+		return MetricSummary{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return MetricSummary{
+		Sum:    sum,
+		Mean:   sum / float64(len(sorted)),
+		Median: percentile(sorted, 0.5),
+		P25:    percentile(sorted, 0.25),
+		P75:    percentile(sorted, 0.75),
+		P90:    percentile(sorted, 0.90),
+	}
+}
+
+// tagMetrics 按指标保存一个 Tag 下每个视频的取值，中位数和分位数需要完整的取值序列
+type tagMetrics map[string][]float64
+
+// addEngagement 把一条视频记录的各项指标计入它的每个 Tag。没有播放数据（Views 为 0，通常是统计接口解析失败）
+// 的视频不计入，否则会以 0 拉低各 Tag 的均值和分位数。
+func addEngagement(values map[string]tagMetrics, video VideoRecord) {
+	if video.Views == 0 {
+		return
+	}
+	for _, tag := range video.Tags {
+		perMetric, ok := values[tag]
+		if !ok {
+			perMetric = make(tagMetrics)
+			values[tag] = perMetric
+		}
+		for _, metric := range engagementMetrics {
+			perMetric[metric] = append(perMetric[metric], videoMetric(video, metric))
+		}
+	}
+}
+
+// countEngagement 汇总各 Tag 的互动指标，没有任何视频带播放数据时返回 nil
+func countEngagement(values map[string]tagMetrics) ([]TagEngagement, map[string][]WeightedTagStat) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	engagement := make([]TagEngagement, 0, len(values))
+	rankings := make(map[string][]WeightedTagStat, len(engagementMetrics))
+
+	for tag, perMetric := range values {
+		item := TagEngagement{
+			Tag:            tag,
+			Videos:         len(perMetric[MetricViews]),
+			Views:          summarize(perMetric[MetricViews]),
+			Likes:          summarize(perMetric[MetricLikes]),
+			Coins:          summarize(perMetric[MetricCoins]),
+			Favorites:      summarize(perMetric[MetricFavorites]),
+			EngagementRate: summarize(perMetric[MetricEngagementRate]),
+		}
+		engagement = append(engagement, item)
+
+		for _, metric := range engagementMetrics {
+			weight := item.metricSummary(metric).Sum
+			// 互动率是比值，累加没有意义，按中位数排序
+			if metric == MetricEngagementRate {
+				weight = item.EngagementRate.Median
+			}
+			rankings[metric] = append(rankings[metric], WeightedTagStat{
+				Tag:    tag,
+				Weight: weight,
+				Videos: item.Videos,
+			})
+		}
+	}
+
+	sort.Slice(engagement, func(i, j int) bool {
+		if engagement[i].Views.Median != engagement[j].Views.Median {
+			return engagement[i].Views.Median > engagement[j].Views.Median
+		}
+		return engagement[i].Tag < engagement[j].Tag
+	})
+
+	for metric, ranking := range rankings {
+		sort.Slice(ranking, func(i, j int) bool {
+			if ranking[i].Weight != ranking[j].Weight {
+				return ranking[i].Weight > ranking[j].Weight
+			}
+			return ranking[i].Tag < ranking[j].Tag
+		})
+		if len(ranking) > weightedRankingSize {
+			ranking = ranking[:weightedRankingSize]
+		}
+		rankings[metric] = ranking
+	}
+
+	return engagement, rankings
+}
+
+func (e TagEngagement) metricSummary(metric string) MetricSummary {
+	switch metric {
+	case MetricViews:
+		return e.Views
+	case MetricLikes:
+		return e.Likes
+	case MetricCoins:
+		return e.Coins
+	case MetricFavorites:
+		return e.Favorites
+	case MetricEngagementRate:
+		return e.EngagementRate
+	default:
+		return MetricSummary{}
+	}
+}
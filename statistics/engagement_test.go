@@ -0,0 +1,39 @@
+package statistics
+
+import "testing"
+
+// 没有播放数据的视频不应以 0 计入各指标，否则会拉低均值和分位数
+func TestEngagementSkipsVideosWithoutStats(t *testing.T) {
+	agg := NewAggregator(nil)
+	err := agg.Merge(&Snapshot{Version: SnapshotVersion, Records: []VideoRecord{
+		{Round: 1, Tags: []string{"游戏"}, Views: 100, Likes: 10},
+		{Round: 1, Tags: []string{"游戏"}, Views: 300, Likes: 30},
+		{Round: 1, Tags: []string{"游戏", "音乐"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := agg.Result()
+	if len(result.Engagement) != 1 {
+		t.Fatalf("只有带播放数据的 Tag 应出现在互动统计中，实际 %+v", result.Engagement)
+	}
+	game := result.Engagement[0]
+	if game.Tag != "游戏" || game.Videos != 2 {
+		t.Fatalf("游戏应统计 2 个视频，实际 %+v", game)
+	}
+	if game.Views.Mean != 200 || game.Views.Median != 200 || game.Views.P25 != 150 {
+		t.Errorf("播放数统计不正确: %+v", game.Views)
+	}
+	if game.EngagementRate.Median != 0.1 {
+		t.Errorf("互动率中位数应为 0.1，实际 %v", game.EngagementRate.Median)
+	}
+}
+
+func TestEngagementWithoutAnyStats(t *testing.T) {
+	agg := NewAggregator(nil)
+	agg.Merge(&Snapshot{Version: SnapshotVersion, Records: []VideoRecord{{Round: 1, Tags: []string{"游戏"}}}})
+	if result := agg.Result(); result.Engagement != nil || result.WeightedRankings != nil {
+		t.Fatalf("没有播放数据时不应输出互动统计: %+v", result.Engagement)
+	}
+}
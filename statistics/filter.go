@@ -0,0 +1,188 @@
+package statistics
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	FilterReasonStopExact  = "stop_exact"
+	FilterReasonStopPrefix = "stop_prefix"
+	FilterReasonStopRegex  = "stop_regex"
+	FilterReasonNotAllowed = "not_allowed"
+)
+
+type TagFilterConfig struct {
+	DisableDefaultStopList bool     `json:"disable_default_stop_list"`
+	StopExact              []string `json:"stop_exact"`
+	StopPrefix             []string `json:"stop_prefix"`
+	StopRegex              []string `json:"stop_regex"`
+	AllowExact             []string `json:"allow_exact"`
+	AllowPrefix            []string `json:"allow_prefix"`
+	AllowRegex             []string `json:"allow_regex"`
+}
+
+type FilterReport struct {
+	KeptOccurrences     int            `json:"kept_occurrences"`
+	FilteredOccurrences int            `json:"filtered_occurrences"`
+	FilteredDistinct    int            `json:"filtered_distinct_tags"`
+	FilteredRatio       float64        `json:"filtered_ratio"`
+	ByReason            map[string]int `json:"by_reason"`
+	FilteredTags        []TagStat      `json:"filtered_tags"`
+}
+
+type tagMatcher struct {
+	exact  map[string]bool
+	prefix []string
+	regex  []*regexp.Regexp
+}
+
+type TagFilter struct {
+	stop  *tagMatcher
+	allow *tagMatcher
+}
+
+func newTagMatcher(exact, prefix, patterns []string) (*tagMatcher, error) {
+	m := &tagMatcher{exact: make(map[string]bool)}
+
+	for _, tag := range exact {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			m.exact[strings.ToLower(tag)] = true
+		}
+	}
+	for _, p := range prefix {
+		if p = strings.TrimSpace(p); p != "" {
+			m.prefix = append(m.prefix, strings.ToLower(p))
+		}
+	}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		// 与完全匹配、前缀规则一致，正则也不区分大小写；需要区分时可在规则开头写 (?-i)
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("无效的Tag过滤正则 %q: %w", pattern, err)
+		}
+		m.regex = append(m.regex, re)
+	}
+
+	return m, nil
+}
+
+func (m *tagMatcher) empty() bool {
+	return len(m.exact) == 0 && len(m.prefix) == 0 && len(m.regex) == 0
+}
+
+func (m *tagMatcher) match(tag string) string {
+	lower := strings.ToLower(tag)
+	if m.exact[lower] {
+		return FilterReasonStopExact
+	}
+	for _, p := range m.prefix {
+		if strings.HasPrefix(lower, p) {
+			return FilterReasonStopPrefix
+		}
+	}
+	for _, re := range m.regex {
+		if re.MatchString(tag) {
+			return FilterReasonStopRegex
+		}
+	}
+	return ""
+}
+
+func NewTagFilter(cfg TagFilterConfig) (*TagFilter, error) {
+	exact := cfg.StopExact
+	prefix := cfg.StopPrefix
+	patterns := cfg.StopRegex
+	if !cfg.DisableDefaultStopList {
+		exact = append(append([]string{}, DefaultStopExact...), exact...)
+		prefix = append(append([]string{}, DefaultStopPrefix...), prefix...)
+		patterns = append(append([]string{}, DefaultStopRegex...), patterns...)
+	}
+
+	stop, err := newTagMatcher(exact, prefix, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	allow, err := newTagMatcher(cfg.AllowExact, cfg.AllowPrefix, cfg.AllowRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TagFilter{stop: stop, allow: allow}, nil
+}
+
+// Check 返回 Tag 被过滤的原因，保留时返回空字符串。
+// allow 列表优先级高于 stop 列表；allow 列表非空时只保留命中的 Tag。
+func (f *TagFilter) Check(tag string) string {
+	if f == nil {
+		return ""
+	}
+	if !f.allow.empty() {
+		if f.allow.match(tag) != "" {
+			return ""
+		}
+		return FilterReasonNotAllowed
+	}
+	return f.stop.match(tag)
+}
+
+type filterTracker struct {
+	filter   *TagFilter
+	kept     int
+	filtered map[string]int
+	reasons  map[string]int
+}
+
+func newFilterTracker(filter *TagFilter) *filterTracker {
+	return &filterTracker{
+		filter:   filter,
+		filtered: make(map[string]int),
+		reasons:  make(map[string]int),
+	}
+}
+
+func (t *filterTracker) keep(tag string) bool {
+	reason := t.filter.Check(tag)
+	if reason == "" {
+		t.kept++
+		return true
+	}
+	t.filtered[tag]++
+	t.reasons[reason]++
+	return false
+}
+
+func (t *filterTracker) report() *FilterReport {
+	// 复制 reasons，聚合器继续接收视频时不会改动已生成的报告
+	report := &FilterReport{
+		KeptOccurrences:  t.kept,
+		FilteredDistinct: len(t.filtered),
+		ByReason:         make(map[string]int, len(t.reasons)),
+		FilteredTags:     make([]TagStat, 0, len(t.filtered)),
+	}
+	for reason, count := range t.reasons {
+		report.ByReason[reason] = count
+	}
+	for tag, count := range t.filtered {
+		report.FilteredOccurrences += count
+		report.FilteredTags = append(report.FilteredTags, TagStat{Tag: tag, Count: count})
+	}
+	if total := report.KeptOccurrences + report.FilteredOccurrences; total > 0 {
+		report.FilteredRatio = float64(report.FilteredOccurrences) / float64(total)
+	}
+
+	sort.Slice(report.FilteredTags, func(i, j int) bool {
+		if report.FilteredTags[i].Count != report.FilteredTags[j].Count {
+			return report.FilteredTags[i].Count > report.FilteredTags[j].Count
+		}
+		return report.FilteredTags[i].Tag < report.FilteredTags[j].Tag
+	})
+
+	return report
+}
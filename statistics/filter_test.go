@@ -0,0 +1,46 @@
+package statistics
+
+import "testing"
+
+func TestTagFilterIgnoresCase(t *testing.T) {
+	filter, err := NewTagFilter(TagFilterConfig{
+		DisableDefaultStopList: true,
+		StopExact:              []string{"VLOG"},
+		StopPrefix:             []string{"bilibili"},
+		StopRegex:              []string{`^mc\d+$`, `(?-i)^ASMR$`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"vlog":       FilterReasonStopExact,
+		"BiliBili新星": FilterReasonStopPrefix,
+		"MC2024":     FilterReasonStopRegex,
+		"mc2024":     FilterReasonStopRegex,
+		"ASMR":       FilterReasonStopRegex,
+		"asmr":       "",
+		"游戏":         "",
+	}
+	for tag, want := range tests {
+		if got := filter.Check(tag); got != want {
+			t.Errorf("Check(%q) = %q，期望 %q", tag, got, want)
+		}
+	}
+}
+
+// 已生成的过滤报告不应随聚合器继续接收视频而变化
+func TestFilterReportIsSnapshot(t *testing.T) {
+	filter, err := NewTagFilter(TagFilterConfig{DisableDefaultStopList: true, StopExact: []string{"广告"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := newFilterTracker(filter)
+	tracker.keep("广告")
+
+	report := tracker.report()
+	tracker.keep("广告")
+	if got := report.ByReason[FilterReasonStopExact]; got != 1 {
+		t.Fatalf("报告生成后 by_reason 被修改为 %d", got)
+	}
+}
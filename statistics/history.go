@@ -0,0 +1,162 @@
+package statistics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type HistoryEntry struct {
+	RunID       string           `json:"run_id"`
+	CrawlTime   string           `json:"crawl_time"`
+	TotalVideos int              `json:"total_videos"`
+	TotalTags   int              `json:"total_tags"`
+	Diversity   DiversityMetrics `json:"diversity"`
+	Tags        []string         `json:"tags"`
+}
+
+type HistoryPoint struct {
+	RunID     string           `json:"run_id"`
+	CrawlTime string           `json:"crawl_time"`
+	Diversity DiversityMetrics `json:"diversity"`
+}
+
+type History struct {
+	path    string
+	Entries []HistoryEntry
+}
+
+// LoadHistory 读取运行历史。出错时仍返回一个空的 History，本次运行照常追加记录：
+// 文件内容损坏时先把它移到 <path>.corrupt-<时间> 留待检查，再从空历史开始，返回的错误说明了原因。
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+
+	entries, err := readHistory(path)
+	if err == nil {
+		h.Entries = entries
+		return h, nil
+	}
+	if !errors.Is(err, errHistoryCorrupt) {
+		return h, err
+	}
+
+	backup := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if renameErr := os.Rename(path, backup); renameErr != nil {
+		return h, fmt.Errorf("%w，移走损坏的文件也失败了: %v", err, renameErr)
+	}
+	return h, fmt.Errorf("%w，已移至 %s，重新开始记录", err, backup)
+}
+
+var errHistoryCorrupt = errors.New("历史记录已损坏")
+
+func readHistory(path string) ([]HistoryEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取历史记录失败: %w", err)
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%w（第 %d 行）: %v", errHistoryCorrupt, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取历史记录失败: %w", err)
+	}
+	return entries, nil
+}
+
+func (h *History) SeenTags() map[string]bool {
+	seen := make(map[string]bool)
+	if h == nil {
+		return seen
+	}
+	for _, entry := range h.Entries {
+		for _, tag := range entry.Tags {
+			seen[tag] = true
+		}
+	}
+	return seen
+}
+
+func (h *History) Recent(n int) []HistoryPoint {
+	if h == nil {
+		return nil
+	}
+
+	start := len(h.Entries) - n
+	if start < 0 {
+		start = 0
+	}
+
+	var points []HistoryPoint
+	for _, entry := range h.Entries[start:] {
+		points = append(points, HistoryPoint{
+			RunID:     entry.RunID,
+			CrawlTime: entry.CrawlTime,
+			Diversity: entry.Diversity,
+		})
+	}
+	return points
+}
+
+func (h *History) Append(result *StatsResult) error {
+	if h == nil || h.path == "" {
+		return nil
+	}
+
+	entry := HistoryEntry{
+		RunID:       result.RunID,
+		CrawlTime:   result.CrawlTime,
+		TotalVideos: result.TotalVideos,
+		TotalTags:   result.TotalTags,
+		Tags:        make([]string, 0, len(result.TagStats)),
+	}
+	if result.Diversity != nil {
+		entry.Diversity = result.Diversity.Run
+	}
+	for _, stat := range result.TagStats {
+		entry.Tags = append(entry.Tags, stat.Tag)
+	}
+	sort.Strings(entry.Tags)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化历史记录失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return fmt.Errorf("创建历史记录目录失败: %w", err)
+	}
+
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开历史记录失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入历史记录失败: %w", err)
+	}
+
+	h.Entries = append(h.Entries, entry)
+	return nil
+}
@@ -0,0 +1,52 @@
+package statistics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadHistoryMovesCorruptFileAside(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.jsonl")
+	corrupt := `{"run_id":"a","tags":["游戏"]}` + "\n" + `{"run_id":` + "\n"
+	if err := os.WriteFile(path, []byte(corrupt), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := LoadHistory(path)
+	if err == nil || !strings.Contains(err.Error(), "第 2 行") {
+		t.Fatalf("应报告损坏的行号，实际: %v", err)
+	}
+	if history == nil || len(history.Entries) != 0 {
+		t.Fatalf("损坏时应返回空历史，实际 %+v", history)
+	}
+
+	backups, _ := filepath.Glob(path + ".corrupt-*")
+	if len(backups) != 1 {
+		t.Fatalf("损坏的文件应被移走，实际 %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != corrupt {
+		t.Errorf("移走的文件内容被修改")
+	}
+
+	// 本次运行的记录仍然写入，并能被下次运行读取
+	if err := history.Append(&StatsResult{RunID: "b", TagStats: []TagStat{{Tag: "音乐", Count: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Entries) != 1 || reloaded.Entries[0].RunID != "b" {
+		t.Fatalf("重新加载的历史 = %+v", reloaded.Entries)
+	}
+}
+
+func TestLoadHistoryMissingFile(t *testing.T) {
+	history, err := LoadHistory(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil || history == nil || len(history.Entries) != 0 {
+		t.Fatalf("文件不存在时应返回空历史: %+v, %v", history, err)
+	}
+}
@@ -0,0 +1,163 @@
+package statistics
+
+import (
+	"sort"
+
+	"biliTagAnalyse/crawler"
+)
+
+const partitionTopTags = 10
+
+type PartitionStat struct {
+	TID           int             `json:"tid"`
+	Name          string          `json:"name"`
+	Count         int             `json:"count"`
+	Share         float64         `json:"share"`
+	SubPartitions []PartitionStat `json:"sub_partitions,omitempty"`
+}
+
+type PartitionTagRanking struct {
+	TID     int       `json:"tid"`
+	Name    string    `json:"name"`
+	Videos  int       `json:"videos"`
+	TopTags []TagStat `json:"top_tags"`
+}
+
+type PartitionRoundRow struct {
+	TID    int    `json:"tid"`
+	Name   string `json:"name"`
+	Counts []int  `json:"counts"`
+}
+
+type PartitionRoundMatrix struct {
+	Rounds []int               `json:"rounds"`
+	Rows   []PartitionRoundRow `json:"rows"`
+}
+
+type partitionKey struct {
+	tid  int
+	name string
+}
+
+type partitionAcc struct {
+	key    partitionKey
+	count  int
+	subs   map[partitionKey]int
+	tags   map[string]int
+	rounds []int
+}
+
+func mainPartitionKey(record VideoRecord) partitionKey {
+	if record.MainName == "" {
+		return partitionKey{tid: record.MainTID, name: crawler.UnknownPartitionName}
+	}
+	return partitionKey{tid: record.MainTID, name: record.MainName}
+}
+
+func subPartitionKey(record VideoRecord) partitionKey {
+	if record.TName == "" {
+		return partitionKey{tid: record.TID, name: crawler.UnknownPartitionName}
+	}
+	return partitionKey{tid: record.TID, name: record.TName}
+}
+
+// addPartition 把一条视频记录计入所属主分区
+func addPartition(accs map[partitionKey]*partitionAcc, record VideoRecord) {
+	key := mainPartitionKey(record)
+	acc, ok := accs[key]
+	if !ok {
+		acc = &partitionAcc{
+			key:  key,
+			subs: make(map[partitionKey]int),
+			tags: make(map[string]int),
+		}
+		accs[key] = acc
+	}
+
+	acc.count++
+	acc.subs[subPartitionKey(record)]++
+	acc.rounds = addRound(acc.rounds, record.Round)
+	for _, tag := range record.Tags {
+		acc.tags[tag]++
+	}
+}
+
+func countPartitions(accs map[partitionKey]*partitionAcc, rounds int) ([]PartitionStat, []PartitionTagRanking, *PartitionRoundMatrix) {
+	total := 0
+	for _, acc := range accs {
+		total += acc.count
+	}
+
+	if total == 0 {
+		return nil, nil, nil
+	}
+
+	ordered := make([]*partitionAcc, 0, len(accs))
+	for _, acc := range accs {
+		ordered = append(ordered, acc)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].count != ordered[j].count {
+			return ordered[i].count > ordered[j].count
+		}
+		return ordered[i].key.tid < ordered[j].key.tid
+	})
+
+	stats := make([]PartitionStat, 0, len(ordered))
+	rankings := make([]PartitionTagRanking, 0, len(ordered))
+	matrix := &PartitionRoundMatrix{Rounds: make([]int, rounds)}
+	for i := range matrix.Rounds {
+		matrix.Rounds[i] = i + 1
+	}
+
+	for _, acc := range ordered {
+		stat := PartitionStat{
+			TID:   acc.key.tid,
+			Name:  acc.key.name,
+			Count: acc.count,
+			Share: float64(acc.count) / float64(total),
+		}
+		for sub, count := range acc.subs {
+			stat.SubPartitions = append(stat.SubPartitions, PartitionStat{
+				TID:   sub.tid,
+				Name:  sub.name,
+				Count: count,
+				Share: float64(count) / float64(acc.count),
+			})
+		}
+		sort.Slice(stat.SubPartitions, func(i, j int) bool {
+			if stat.SubPartitions[i].Count != stat.SubPartitions[j].Count {
+				return stat.SubPartitions[i].Count > stat.SubPartitions[j].Count
+			}
+			return stat.SubPartitions[i].TID < stat.SubPartitions[j].TID
+		})
+		stats = append(stats, stat)
+
+		ranking := PartitionTagRanking{
+			TID:    acc.key.tid,
+			Name:   acc.key.name,
+			Videos: acc.count,
+		}
+		for tag, count := range acc.tags {
+			ranking.TopTags = append(ranking.TopTags, TagStat{Tag: tag, Count: count})
+		}
+		sort.Slice(ranking.TopTags, func(i, j int) bool {
+			if ranking.TopTags[i].Count != ranking.TopTags[j].Count {
+				return ranking.TopTags[i].Count > ranking.TopTags[j].Count
+			}
+			return ranking.TopTags[i].Tag < ranking.TopTags[j].Tag
+		})
+		if len(ranking.TopTags) > partitionTopTags {
+			ranking.TopTags = ranking.TopTags[:partitionTopTags]
+		}
+		rankings = append(rankings, ranking)
+
+		matrix.Rows = append(matrix.Rows, PartitionRoundRow{
+			TID:    acc.key.tid,
+			Name:   acc.key.name,
+			Counts: padRounds(acc.rounds, rounds),
+		})
+	}
+
+	return stats, rankings, matrix
+}
@@ -0,0 +1,70 @@
+package statistics
+
+type PersonalizationScore struct {
+	LoggedIn          string    `json:"logged_in"`
+	Anonymous         string    `json:"anonymous"`
+	TagJaccard        float64   `json:"tag_jaccard"`
+	TagCosine         float64   `json:"tag_cosine"`
+	Spearman          float64   `json:"spearman"`
+	PartitionDistance float64   `json:"partition_distance"`
+	Score             float64   `json:"score"`
+	LoggedInOnly      []TagStat `json:"logged_in_only"`
+	AnonymousOnly     []TagStat `json:"anonymous_only"`
+}
+
+// partitionDistance 为两次爬取主分区分布的总变差距离，取值 0~1。
+func partitionDistance(a, b []PartitionStat) float64 {
+	shares := make(map[string][2]float64)
+	for _, p := range a {
+		v := shares[p.Name]
+		v[0] = p.Share
+		shares[p.Name] = v
+	}
+	for _, p := range b {
+		v := shares[p.Name]
+		v[1] = p.Share
+		shares[p.Name] = v
+	}
+
+	sum := 0.0
+	for _, v := range shares {
+		d := v[0] - v[1]
+		if d < 0 {
+			d = -d
+		}
+		sum += d
+	}
+	return sum / 2
+}
+
+// ComputePersonalization 比较登录与未登录推荐流的差异。
+// 个性化分数 = (1 - Tag 频次余弦相似度 + 主分区分布总变差距离) / 2，0 表示与匿名推荐完全一致，1 表示完全不同。
+func ComputePersonalization(loggedIn, anonymous *StatsResult) *PersonalizationScore {
+	loggedInLabel := loggedIn.Label
+	if loggedInLabel == "" {
+		loggedInLabel = "logged_in"
+	}
+	anonymousLabel := anonymous.Label
+	if anonymousLabel == "" {
+		anonymousLabel = "anonymous"
+	}
+
+	cmp := CompareFeeds([]LabeledStats{
+		{Label: loggedInLabel, Stats: loggedIn},
+		{Label: anonymousLabel, Stats: anonymous},
+	}, DefaultCompareTopK)
+
+	score := &PersonalizationScore{
+		LoggedIn:          loggedInLabel,
+		Anonymous:         anonymousLabel,
+		TagJaccard:        cmp.Jaccard[0][1],
+		TagCosine:         cmp.Cosine[0][1],
+		Spearman:          cmp.Spearman[0][1],
+		PartitionDistance: partitionDistance(loggedIn.PartitionStats, anonymous.PartitionStats),
+		LoggedInOnly:      cmp.UniqueTags[loggedInLabel],
+		AnonymousOnly:     cmp.UniqueTags[anonymousLabel],
+	}
+	score.Score = (1 - score.TagCosine + score.PartitionDistance) / 2
+
+	return score
+}
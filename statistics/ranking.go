@@ -0,0 +1,59 @@
+package statistics
+
+import "sort"
+
+// rankTags 按固定规则排序，保证相同输入得到相同输出：
+//  1. 出现次数降序
+//  2. 文档频次（包含该 Tag 的不同视频数）降序
+//  3. 首次出现顺序升序
+//  4. Tag 字典序升序
+//
+// docFreq 或 firstSeen 为 nil 时跳过对应规则。
+func rankTags(counts, docFreq, firstSeen map[string]int) []TagStat {
+	stats := make([]TagStat, 0, len(counts))
+	for tag, count := range counts {
+		stat := TagStat{Tag: tag, Count: count}
+		if docFreq != nil {
+			stat.DocFreq = docFreq[tag]
+		}
+		if firstSeen != nil {
+			stat.FirstSeen = firstSeen[tag]
+		}
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.DocFreq != b.DocFreq {
+			return a.DocFreq > b.DocFreq
+		}
+		if a.FirstSeen != b.FirstSeen {
+			return a.FirstSeen < b.FirstSeen
+		}
+		return a.Tag < b.Tag
+	})
+
+	assignRanks(stats)
+	return stats
+}
+
+// assignRanks 按出现次数计算并列名次：Rank 为竞争排名（1,2,2,4），DenseRank 为密集排名（1,2,2,3）。
+// stats 需已按次数降序排列。
+func assignRanks(stats []TagStat) {
+	for i := range stats {
+		if i > 0 && stats[i].Count == stats[i-1].Count {
+			stats[i].Rank = stats[i-1].Rank
+			stats[i].DenseRank = stats[i-1].DenseRank
+			continue
+		}
+		stats[i].Rank = i + 1
+		if i == 0 {
+			stats[i].DenseRank = 1
+		} else {
+			stats[i].DenseRank = stats[i-1].DenseRank + 1
+		}
+	}
+}
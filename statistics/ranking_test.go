@@ -0,0 +1,83 @@
+package statistics
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"reflect"
+	"testing"
+
+	"biliTagAnalyse/crawler"
+)
+
+func loadRankingFixture(t *testing.T) []*crawler.VideoInfo {
+	t.Helper()
+	rounds, err := crawler.LoadVideos("testdata/ranking_videos.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var videos []*crawler.VideoInfo
+	for _, round := range rounds {
+		videos = append(videos, round...)
+	}
+	return videos
+}
+
+// 并发爬取时视频到达顺序不确定，排名（包括 first_seen）只能取决于轮次和视频在链接列表中的位置
+func TestRankingIgnoresArrivalOrder(t *testing.T) {
+	data, err := os.ReadFile("testdata/ranking_golden.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []TagStat
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	videos := loadRankingFixture(t)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 20; i++ {
+		order := append([]*crawler.VideoInfo(nil), videos...)
+		if i > 0 {
+			rng.Shuffle(len(order), func(a, b int) { order[a], order[b] = order[b], order[a] })
+		}
+
+		agg := NewAggregator(nil)
+		for _, video := range order {
+			agg.Add(video, video.Round)
+		}
+		if got := agg.Result().TagStats; !reflect.DeepEqual(got, want) {
+			t.Fatalf("第 %d 种到达顺序的排名与 golden 文件不一致:\n got: %+v\nwant: %+v", i, got, want)
+		}
+	}
+}
+
+func TestAssignRanksTies(t *testing.T) {
+	tests := []struct {
+		name      string
+		counts    []int
+		rank      []int
+		denseRank []int
+	}{
+		{"无并列", []int{5, 3, 1}, []int{1, 2, 3}, []int{1, 2, 3}},
+		{"中间并列", []int{5, 3, 3, 1}, []int{1, 2, 2, 4}, []int{1, 2, 2, 3}},
+		{"首位并列", []int{4, 4, 4, 2, 2}, []int{1, 1, 1, 4, 4}, []int{1, 1, 1, 2, 2}},
+		{"全部并列", []int{2, 2}, []int{1, 1}, []int{1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := make([]TagStat, len(tt.counts))
+			for i, count := range tt.counts {
+				stats[i].Count = count
+			}
+			assignRanks(stats)
+			for i, stat := range stats {
+				if stat.Rank != tt.rank[i] || stat.DenseRank != tt.denseRank[i] {
+					t.Errorf("第 %d 项: rank=%d dense_rank=%d，期望 rank=%d dense_rank=%d",
+						i, stat.Rank, stat.DenseRank, tt.rank[i], tt.denseRank[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package statistics
+
+import (
+	"math/rand/v2"
+	"sort"
+)
+
+const (
+	DefaultStabilityTopK = 10
+	bootstrapSamples     = 200
+	shareCITop           = 20
+)
+
+type TagShareCI struct {
+	Tag   string  `json:"tag"`
+	Share float64 `json:"share"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+type SamplingReport struct {
+	Rounds               int          `json:"rounds"`
+	Videos               int          `json:"videos"`
+	ObservedTags         int          `json:"observed_tags"`
+	Singletons           int          `json:"singletons"`
+	Doubletons           int          `json:"doubletons"`
+	Chao1                float64      `json:"chao1"`
+	UnseenEstimate       float64      `json:"unseen_estimate"`
+	Coverage             float64      `json:"coverage"`
+	Accumulation         []int        `json:"accumulation"`
+	TopK                 int          `json:"top_k"`
+	TopChangeProbability float64      `json:"top_change_probability"`
+	BootstrapSamples     int          `json:"bootstrap_samples"`
+	ShareCI              []TagShareCI `json:"share_ci"`
+}
+
+func topKSet(counts map[string]int, k int) map[string]bool {
+	stats := make([]TagStat, 0, len(counts))
+	for tag, count := range counts {
+		stats = append(stats, TagStat{Tag: tag, Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Tag < stats[j].Tag
+	})
+
+	set := make(map[string]bool, k)
+	for i := 0; i < len(stats) && i < k; i++ {
+		set[stats[i].Tag] = true
+	}
+	return set
+}
+
+func sameSet(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for tag := range a {
+		if !b[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// computeSampling 评估当前样本是否足够：
+// 用 bootstrap（按视频重抽样，视频数超出抽样上限时从蓄水池样本中重抽）估计 Tag 占比的 95% 置信区间；
+// 从已有轮次中随机抽一轮追加，估计再爬一轮后 Top K 集合发生变化的概率；
+// 用 Chao1 估计尚未观测到的 Tag 数量。run 为整次运行的摘要，其中的计数只读不改。
+func computeSampling(rounds []roundSample, run roundSample, k int) *SamplingReport {
+	if k <= 0 {
+		k = DefaultStabilityTopK
+	}
+
+	counts := run.tags
+	report := &SamplingReport{
+		Rounds:           len(rounds),
+		TopK:             k,
+		BootstrapSamples: bootstrapSamples,
+	}
+
+	observed := make(map[string]bool)
+	for _, round := range rounds {
+		for tag := range round.tags {
+			observed[tag] = true
+		}
+		report.Accumulation = append(report.Accumulation, len(observed))
+	}
+
+	report.Videos = run.videos
+	report.ObservedTags = len(counts)
+	if report.Videos == 0 {
+		return report
+	}
+
+	occurrences := 0
+	for _, count := range counts {
+		occurrences += count
+		switch count {
+		case 1:
+			report.Singletons++
+		case 2:
+			report.Doubletons++
+		}
+	}
+
+	f1 := float64(report.Singletons)
+	f2 := float64(report.Doubletons)
+	if report.Singletons > 1 {
+		report.UnseenEstimate = f1 * (f1 - 1) / (2 * (f2 + 1))
+	}
+	report.Chao1 = float64(report.ObservedTags) + report.UnseenEstimate
+	report.Coverage = 1 - f1/float64(occurrences)
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	report.ShareCI = bootstrapShares(run.sample.items, run.videos, counts, rng)
+	report.TopChangeProbability = topChangeProbability(rounds, counts, k, rng)
+
+	return report
+}
+
+// bootstrapShares 每次从 videos 中有放回地抽取 n 个视频（n 为实际视频数），videos 可以是全部视频或其均匀抽样
+func bootstrapShares(videos [][]string, n int, counts map[string]int, rng *rand.Rand) []TagShareCI {
+	stats := make([]TagStat, 0, len(counts))
+	for tag, count := range counts {
+		stats = append(stats, TagStat{Tag: tag, Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Tag < stats[j].Tag
+	})
+	if len(stats) > shareCITop {
+		stats = stats[:shareCITop]
+	}
+
+	index := make(map[string]int, len(stats))
+	for i, stat := range stats {
+		index[stat.Tag] = i
+	}
+
+	samples := make([][]float64, len(stats))
+	for b := 0; b < bootstrapSamples; b++ {
+		hits := make([]int, len(stats))
+		for i := 0; i < n; i++ {
+			for _, tag := range videos[rng.IntN(len(videos))] {
+				if idx, ok := index[tag]; ok {
+					hits[idx]++
+				}
+			}
+		}
+		for idx, h := range hits {
+			samples[idx] = append(samples[idx], float64(h)/float64(n))
+		}
+	}
+
+	cis := make([]TagShareCI, 0, len(stats))
+	for idx, stat := range stats {
+		sort.Float64s(samples[idx])
+		cis = append(cis, TagShareCI{
+			Tag:   stat.Tag,
+			Share: float64(stat.Count) / float64(n),
+			Lower: percentile(samples[idx], 0.025),
+			Upper: percentile(samples[idx], 0.975),
+		})
+	}
+	return cis
+}
+
+func topChangeProbability(rounds []roundSample, counts map[string]int, k int, rng *rand.Rand) float64 {
+	if len(rounds) == 0 {
+		return 1
+	}
+
+	current := topKSet(counts, k)
+	changed := 0
+	for b := 0; b < bootstrapSamples; b++ {
+		next := make(map[string]int, len(counts))
+		for tag, count := range counts {
+			next[tag] = count
+		}
+		for tag, count := range rounds[rng.IntN(len(rounds))].tags {
+			next[tag] += count
+		}
+		if !sameSet(current, topKSet(next, k)) {
+			changed++
+		}
+	}
+	return float64(changed) / float64(bootstrapSamples)
+}
@@ -4,84 +4,65 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
-	"time"
 
 	"biliTagAnalyse/crawler"
 )
 
 type TagStat struct {
-	Tag   string `json:"tag"`
-	Count int    `json:"count"`
+	Tag       string `json:"tag"`
+	Count     int    `json:"count"`
+	Rank      int    `json:"rank,omitempty"`
+	DenseRank int    `json:"dense_rank,omitempty"`
+	DocFreq   int    `json:"doc_freq,omitempty"`
+	FirstSeen int    `json:"first_seen,omitempty"`
 }
 
 type StatsResult struct {
-	CrawlTime   string    `json:"crawl_time"`
-	TotalVideos int       `json:"total_videos"`
-	TotalTags   int       `json:"total_tags"`
-	TagStats    []TagStat `json:"tag_stats"`
+	RunID        string          `json:"run_id,omitempty"`
+	Label        string          `json:"label,omitempty"`
+	CrawlTime    string          `json:"crawl_time"`
+	StartTime    string          `json:"start_time,omitempty"`
+	EndTime      string          `json:"end_time,omitempty"`
+	TotalVideos  int             `json:"total_videos"`
+	TotalTags    int             `json:"total_tags"`
+	TagStats     []TagStat       `json:"tag_stats"`
+	FilterReport *FilterReport   `json:"filter_report,omitempty"`
+	CoOccurrence []TagPair       `json:"co_occurrence,omitempty"`
+	TagRounds    *TagRoundMatrix `json:"tag_rounds,omitempty"`
+
+	PartitionStats  []PartitionStat       `json:"partition_stats,omitempty"`
+	PartitionTags   []PartitionTagRanking `json:"partition_tags,omitempty"`
+	PartitionRounds *PartitionRoundMatrix `json:"partition_rounds,omitempty"`
+
+	Engagement       []TagEngagement              `json:"engagement_stats,omitempty"`
+	WeightedRankings map[string][]WeightedTagStat `json:"weighted_rankings,omitempty"`
+
+	CreatorStats         []CreatorStat         `json:"creator_stats,omitempty"`
+	CreatorConcentration *CreatorConcentration `json:"creator_concentration,omitempty"`
+
+	Diversity *DiversityReport `json:"diversity,omitempty"`
+	Sampling  *SamplingReport  `json:"sampling,omitempty"`
+
+	Baseline        *StatsResult          `json:"baseline,omitempty"`
+	Personalization *PersonalizationScore `json:"personalization,omitempty"`
 }
 
-func CountTags(videos []*crawler.VideoInfo) *StatsResult {
-	tagCount := make(map[string]int)
-
+func CountTags(videos []*crawler.VideoInfo, filter *TagFilter) *StatsResult {
+	agg := NewAggregator(filter)
 	for _, video := range videos {
-		for _, tag := range video.Tags {
-			tagCount[tag]++
-		}
-	}
-
-	var tagStats []TagStat
-	for tag, count := range tagCount {
-		tagStats = append(tagStats, TagStat{Tag: tag, Count: count})
-	}
-
-	sort.Slice(tagStats, func(i, j int) bool {
-		return tagStats[i].Count > tagStats[j].Count
-	})
-
-	totalVideos := len(videos)
-	totalTags := len(tagStats)
-
-	return &StatsResult{
-		CrawlTime:   time.Now().Format("2006-01-02 15:04:05"),
-		TotalVideos: totalVideos,
-		TotalTags:   totalTags,
-		TagStats:    tagStats,
+		agg.Add(video, 1)
 	}
+	return agg.Result()
 }
 
-func CountTagsMultipleRounds(allVideos [][]*crawler.VideoInfo) *StatsResult {
-	tagCount := make(map[string]int)
-
-	for _, roundVideos := range allVideos {
+func CountTagsMultipleRounds(allVideos [][]*crawler.VideoInfo, filter *TagFilter) *StatsResult {
+	agg := NewAggregator(filter)
+	for i, roundVideos := range allVideos {
 		for _, video := range roundVideos {
-			for _, tag := range video.Tags {
-				tagCount[tag]++
-			}
+			agg.Add(video, i+1)
 		}
 	}
-
-	var tagStats []TagStat
-	for tag, count := range tagCount {
-		tagStats = append(tagStats, TagStat{Tag: tag, Count: count})
-	}
-
-	sort.Slice(tagStats, func(i, j int) bool {
-		return tagStats[i].Count > tagStats[j].Count
-	})
-
-	totalVideos := 0
-	for _, roundVideos := range allVideos {
-		totalVideos += len(roundVideos)
-	}
-
-	return &StatsResult{
-		CrawlTime:   time.Now().Format("2006-01-02 15:04:05"),
-		TotalVideos: totalVideos,
-		TotalTags:   len(tagCount),
-		TagStats:    tagStats,
-	}
+	return agg.Result()
 }
 
 func SaveResults(result *StatsResult, outputPath string) error {
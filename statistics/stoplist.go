@@ -0,0 +1,46 @@
+package statistics
+
+// 内置的 B站 平台活动/话题类 Tag，这些 Tag 由投稿工具或活动自动附加，不反映视频内容。
+var DefaultStopExact = []string{
+	"必剪创作",
+	"必剪",
+	"必剪模板",
+	"每日打卡",
+	"日常打卡",
+	"打卡挑战",
+	"全能打卡挑战",
+	"热点打卡",
+	"新星计划",
+	"bilibili新星计划",
+	"星计划",
+	"bilibili星计划",
+	"UP主成长计划",
+	"创作激励",
+	"创作者激励计划",
+	"知识分享官",
+	"生活记录官",
+	"科技猎手",
+	"视频投稿激励",
+	"单机游戏激励计划",
+	"手机游戏激励计划",
+	"网络游戏激励计划",
+	"bilibili",
+	"哔哩哔哩",
+	"B站",
+}
+
+var DefaultStopPrefix = []string{
+	"#",
+	"打卡挑战",
+	"新星计划",
+	"星海计划",
+	"bilibili新星",
+}
+
+var DefaultStopRegex = []string{
+	`打卡(挑战|计划|活动)?$`,
+	`(激励|扶持|成长|星火|新星)计划`,
+	`(征稿|投稿)(活动|大赛|季)?$`,
+	`^20\d{2}.*(征稿|活动|挑战赛)`,
+	`挑战赛$`,
+}
@@ -0,0 +1,50 @@
+package statistics
+
+const tagTrendLimit = 20
+
+type TagRoundRow struct {
+	Tag    string `json:"tag"`
+	Counts []int  `json:"counts"`
+}
+
+// TagRoundMatrix 记录排名靠前的 Tag 在每轮中的出现次数，用于绘制趋势线
+type TagRoundMatrix struct {
+	Rounds []int         `json:"rounds"`
+	Rows   []TagRoundRow `json:"rows"`
+}
+
+// addRound 为第 round 轮（从 1 开始）计数加一，切片按需扩展
+func addRound(counts []int, round int) []int {
+	for len(counts) < round {
+		counts = append(counts, 0)
+	}
+	counts[round-1]++
+	return counts
+}
+
+// padRounds 返回长度为 rounds 的计数副本，之后才出现的轮次补 0
+func padRounds(counts []int, rounds int) []int {
+	padded := make([]int, rounds)
+	copy(padded, counts)
+	return padded
+}
+
+func countTagRounds(tagRounds map[string][]int, rounds int, tagStats []TagStat) *TagRoundMatrix {
+	if rounds == 0 || len(tagStats) == 0 {
+		return nil
+	}
+
+	top := tagStats
+	if len(top) > tagTrendLimit {
+		top = top[:tagTrendLimit]
+	}
+
+	matrix := &TagRoundMatrix{Rounds: make([]int, rounds)}
+	for i := range matrix.Rounds {
+		matrix.Rounds[i] = i + 1
+	}
+	for _, stat := range top {
+		matrix.Rows = append(matrix.Rows, TagRoundRow{Tag: stat.Tag, Counts: padRounds(tagRounds[stat.Tag], rounds)})
+	}
+	return matrix
+}
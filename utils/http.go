@@ -1,16 +1,28 @@
 package utils
 
 import (
+	"crypto/rand"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 )
 
+// RequestObserver 接收请求过程中的事件，用于进度展示；HTTPClient.Observer 为 nil 时不上报
+type RequestObserver interface {
+	RequestStarted(url string)
+	RequestFinished(url string, err error)
+	RequestRetry(url string, attempt int, err error)
+	// RateLimited 在 B站返回 HTTP 412 / 429 时调用，until 取自 Retry-After，没有该响应头时为当前时间
+	RateLimited(until time.Time)
+}
+
 type HTTPClient struct {
-	Client *http.Client
-	Cookie string
+	Client   *http.Client
+	Cookie   string
+	Observer RequestObserver
 }
 
 func NewHTTPClient(cookie string) *HTTPClient {
@@ -50,6 +62,11 @@ func (c *HTTPClient) Get(url string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	// 限流只通知观察者用于展示，响应照常返回，重试与否仍由调用方按原有逻辑决定
+	if c.Observer != nil && (resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusTooManyRequests) {
+		c.Observer.RateLimited(retryAfter(resp.Header.Get("Retry-After")))
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("读取响应失败: %w", err)
@@ -62,18 +79,59 @@ func RetryGet(client *HTTPClient, url string, retryCount int, retryDelay int) ([
 	var err error
 	var body []byte
 
+	if client.Observer != nil {
+		client.Observer.RequestStarted(url)
+	}
+
 	for i := 0; i < retryCount; i++ {
 		body, err = client.Get(url)
 		if err == nil {
+			if client.Observer != nil {
+				client.Observer.RequestFinished(url, nil)
+			}
 			return body, nil
 		}
 
 		log.Printf("请求失败 (尝试 %d/%d): %v", i+1, retryCount, err)
 
 		if i < retryCount-1 {
+			if client.Observer != nil {
+				client.Observer.RequestRetry(url, i+1, err)
+			}
 			time.Sleep(time.Duration(retryDelay) * time.Second)
 		}
 	}
 
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", retryCount, err)
+	err = fmt.Errorf("重试 %d 次后仍然失败: %w", retryCount, err)
+	if client.Observer != nil {
+		client.Observer.RequestFinished(url, err)
+	}
+	return nil, err
+}
+
+// retryAfter 解析 Retry-After 响应头（秒数或 HTTP 日期），无法解析时返回当前时间
+func retryAfter(value string) time.Time {
+	now := time.Now()
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	return now
+}
+
+// GenerateBuvid3 生成与浏览器格式一致的 buvid3 设备标识，供匿名模式使用。
+func GenerateBuvid3() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	uuid := fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	return fmt.Sprintf("%s%05dinfoc", uuid, time.Now().UnixNano()%100000)
+}
+
+func AnonymousCookie() string {
+	return fmt.Sprintf("buvid3=%s; b_nut=%d", GenerateBuvid3(), time.Now().Unix())
 }
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	limited []time.Time
+}
+
+func (o *recordingObserver) RequestStarted(url string)                       {}
+func (o *recordingObserver) RequestFinished(url string, err error)           {}
+func (o *recordingObserver) RequestRetry(url string, attempt int, err error) {}
+func (o *recordingObserver) RateLimited(until time.Time)                     { o.limited = append(o.limited, until) }
+
+// 412/429 只通知观察者，Get 仍像以前一样返回响应体，不改变调用方的重试逻辑
+func TestGetReportsRateLimitWithoutFailing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		io.WriteString(w, "风控页面")
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client := NewHTTPClient("")
+	client.Observer = observer
+
+	body, err := client.Get(server.URL)
+	if err != nil || string(body) != "风控页面" {
+		t.Fatalf("Get = %q, %v，期望照常返回响应体", body, err)
+	}
+	if len(observer.limited) != 1 {
+		t.Fatalf("应通知观察者 1 次，实际 %d 次", len(observer.limited))
+	}
+	if wait := time.Until(observer.limited[0]); wait < 25*time.Second || wait > 30*time.Second {
+		t.Errorf("限流结束时间应取自 Retry-After，实际还剩 %v", wait)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if got := retryAfter(""); time.Since(got) > time.Second {
+		t.Errorf("没有 Retry-After 时应返回当前时间，实际 %v", got)
+	}
+	date := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	if got := retryAfter(date.Format(http.TimeFormat)); !got.Equal(date) {
+		t.Errorf("HTTP 日期格式解析为 %v，期望 %v", got, date)
+	}
+}